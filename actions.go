@@ -0,0 +1,332 @@
+// Action delivery — process_watcher's notifications used to be hardcoded to
+// org.freedesktop.Notifications. This file introduces a pluggable Action
+// interface plus the non-chat delivery targets (dbus, webhook, exec, file);
+// see actions_chat.go for the chat connectors and actions_syslog_*.go for
+// syslog.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// RenderedMessage is the already-templated title/body/urgency a criterion
+// produced for one event; every Action delivers the same RenderedMessage
+// regardless of transport.
+type RenderedMessage struct {
+	Title   string
+	Body    string
+	Urgency string
+}
+
+// Action is one delivery target, referenced by name from a criterion's
+// "actions" list and defined in the top-level "actions" config section.
+type Action interface {
+	Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error
+}
+
+// actionWorkerPoolSize bounds how many Deliver calls run concurrently across
+// the whole watcher, so a slow webhook or exec hook can't stall event
+// consumption.
+const actionWorkerPoolSize = 16
+
+// defaultActionTimeout bounds a single Deliver call when the action itself
+// has no more specific timeout configured (webhook, exec).
+const defaultActionTimeout = 10 * time.Second
+
+// buildActions turns the top-level "actions" config section into a name ->
+// Action registry. A "dbus" entry is always present, even if raw doesn't
+// define one, so criteria that never reference the actions section keep the
+// pre-action-plugin default of D-Bus-only delivery.
+func buildActions(raw map[string]actionConfigRaw) (map[string]Action, error) {
+	out := map[string]Action{
+		"dbus": dbusAction{},
+	}
+	for name, a := range raw {
+		act, err := buildAction(name, a)
+		if err != nil {
+			return nil, err
+		}
+		out[name] = act
+	}
+	return out, nil
+}
+
+func buildAction(name string, a actionConfigRaw) (Action, error) {
+	switch a.Type {
+	case "", "dbus":
+		return dbusAction{}, nil
+	case "webhook":
+		if a.URL == "" {
+			return nil, fmt.Errorf("action %q: webhook requires \"url\"", name)
+		}
+		retries := a.MaxRetries
+		if retries <= 0 {
+			retries = 3
+		}
+		return &webhookAction{url: a.URL, secret: a.Secret, maxRetries: retries}, nil
+	case "exec":
+		if len(a.Command) == 0 {
+			return nil, fmt.Errorf("action %q: exec requires \"command\"", name)
+		}
+		timeout := defaultActionTimeout
+		if a.Timeout != "" {
+			d, err := time.ParseDuration(a.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("action %q: invalid timeout: %w", name, err)
+			}
+			timeout = d
+		}
+		return &execAction{command: a.Command, timeout: timeout}, nil
+	case "syslog":
+		return newSyslogAction(name, a)
+	case "file":
+		if a.Path == "" {
+			return nil, fmt.Errorf("action %q: file requires \"path\"", name)
+		}
+		maxSizeMB := a.MaxSizeMB
+		if maxSizeMB <= 0 {
+			maxSizeMB = 100
+		}
+		maxBackups := a.MaxBackups
+		if maxBackups <= 0 {
+			maxBackups = 3
+		}
+		return &fileAction{path: a.Path, maxSizeMB: maxSizeMB, maxBackups: maxBackups}, nil
+	case "chat":
+		conn, err := buildChatConnector(name, a)
+		if err != nil {
+			return nil, err
+		}
+		return &chatAction{connector: conn}, nil
+	default:
+		return nil, fmt.Errorf("action %q: unknown type %q", name, a.Type)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// dbus — see actions_dbus.go / actions_dbus_freebsd.go for dbusAction itself
+// ---------------------------------------------------------------------------
+
+// ---------------------------------------------------------------------------
+// webhook
+// ---------------------------------------------------------------------------
+
+// webhookActionPayload is the JSON body POSTed to a webhook action's url.
+type webhookActionPayload struct {
+	Event     string `json:"event"`
+	Pid       int32  `json:"pid"`
+	Ppid      int32  `json:"ppid"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Urgency   string `json:"urgency"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// webhookAction POSTs a JSON payload to url, signing the body with an
+// X-Pnotify-Signature HMAC-SHA256 header when secret is set, and retrying
+// with exponential backoff on transport errors or a 5xx response.
+type webhookAction struct {
+	url        string
+	secret     string
+	maxRetries int
+}
+
+func (a *webhookAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	ts := ev.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	body, err := json.Marshal(webhookActionPayload{
+		Event:     ev.Kind,
+		Pid:       ev.Pid,
+		Ppid:      ev.ParentPid,
+		Title:     msg.Title,
+		Body:      msg.Body,
+		Urgency:   msg.Urgency,
+		Timestamp: ts.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("webhook: marshal payload: %w", err)
+	}
+
+	var lastErr error
+	backoff := 500 * time.Millisecond
+	for attempt := 0; attempt <= a.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("webhook: build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if a.secret != "" {
+			mac := hmac.New(sha256.New, []byte(a.secret))
+			mac.Write(body)
+			req.Header.Set("X-Pnotify-Signature", hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("webhook: %w", err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			lastErr = fmt.Errorf("webhook: %s returned %d", a.url, resp.StatusCode)
+			continue
+		}
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("webhook: %s returned %d", a.url, resp.StatusCode)
+		}
+		return nil
+	}
+	return fmt.Errorf("webhook: giving up after %d attempts: %w", a.maxRetries+1, lastErr)
+}
+
+// ---------------------------------------------------------------------------
+// exec
+// ---------------------------------------------------------------------------
+
+// execAction runs command with the event serialized as PNOTIFY_* environment
+// variables, killing it if it outlives timeout. Combined stdout/stderr is
+// captured to the log rather than inherited, so a noisy hook doesn't clutter
+// process_watcher's own output.
+type execAction struct {
+	command []string
+	timeout time.Duration
+}
+
+func (a *execAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, a.command[0], a.command[1:]...)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("PNOTIFY_EVENT=%s", ev.Kind),
+		fmt.Sprintf("PNOTIFY_PID=%d", ev.Pid),
+		fmt.Sprintf("PNOTIFY_PPID=%d", ev.ParentPid),
+		fmt.Sprintf("PNOTIFY_EXIT_CODE=%d", ev.ExitCode),
+		fmt.Sprintf("PNOTIFY_SIGNAL=%d", ev.ExitSignal),
+		fmt.Sprintf("PNOTIFY_TITLE=%s", msg.Title),
+		fmt.Sprintf("PNOTIFY_BODY=%s", msg.Body),
+		fmt.Sprintf("PNOTIFY_URGENCY=%s", msg.Urgency),
+	)
+
+	out, err := cmd.CombinedOutput()
+	if len(out) > 0 {
+		log.Printf("exec action %v: %s", a.command, bytes.TrimRight(out, "\n"))
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("exec: %v timed out after %v", a.command, a.timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("exec: %v: %w", a.command, err)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// file
+// ---------------------------------------------------------------------------
+
+// fileActionRecord is one JSONL line appended by a file action.
+type fileActionRecord struct {
+	Event     string `json:"event"`
+	Pid       int32  `json:"pid"`
+	Ppid      int32  `json:"ppid"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Urgency   string `json:"urgency"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// fileAction appends one JSON object per line to path, rotating to
+// path.1, path.2, ... once the file exceeds maxSizeMB, keeping at most
+// maxBackups old files. mu serializes rotate+write across the action
+// worker pool, since two concurrent deliveries interleaving a write with
+// a rotation rename would otherwise lose a line or write to a just-rotated
+// path.
+type fileAction struct {
+	path       string
+	maxSizeMB  int
+	maxBackups int
+
+	mu sync.Mutex
+}
+
+func (a *fileAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	ts := ev.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	line, err := json.Marshal(fileActionRecord{
+		Event:     ev.Kind,
+		Pid:       ev.Pid,
+		Ppid:      ev.ParentPid,
+		Title:     msg.Title,
+		Body:      msg.Body,
+		Urgency:   msg.Urgency,
+		Timestamp: ts.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("file action: marshal record: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.rotateIfNeeded(); err != nil {
+		log.Printf("file action: rotate %s: %v", a.path, err)
+	}
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("file action: open %s: %w", a.path, err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// rotateIfNeeded must be called with a.mu held.
+func (a *fileAction) rotateIfNeeded() error {
+	info, err := os.Stat(a.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if info.Size() < int64(a.maxSizeMB)*1024*1024 {
+		return nil
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", a.path, a.maxBackups))
+	for i := a.maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", a.path, i)
+		dst := fmt.Sprintf("%s.%d", a.path, i+1)
+		if _, err := os.Stat(src); err == nil {
+			os.Rename(src, dst)
+		}
+	}
+	return os.Rename(a.path, a.path+".1")
+}
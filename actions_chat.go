@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// chatConnector is one chat protocol's delivery mechanism, matching how
+// matterbridge keys a connector by protocol rather than hardcoding a single
+// transport for every "chat" action.
+type chatConnector interface {
+	send(ctx context.Context, title, body string) error
+}
+
+// chatAction adapts a chatConnector to the Action interface, joining
+// title/body into the single message most chat protocols expect.
+type chatAction struct {
+	connector chatConnector
+}
+
+func (a *chatAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	return a.connector.send(ctx, msg.Title, msg.Body)
+}
+
+func buildChatConnector(name string, a actionConfigRaw) (chatConnector, error) {
+	switch a.Protocol {
+	case "slack", "discord", "generic":
+		if a.URL == "" {
+			return nil, fmt.Errorf("action %q: chat protocol %q requires \"url\"", name, a.Protocol)
+		}
+		return &webhookChatConnector{url: a.URL, protocol: a.Protocol}, nil
+	case "irc":
+		if a.IRCServer == "" || a.IRCChannel == "" {
+			return nil, fmt.Errorf("action %q: chat protocol \"irc\" requires \"irc_server\" and \"irc_channel\"", name)
+		}
+		nick := a.IRCNick
+		if nick == "" {
+			nick = "pnotify"
+		}
+		return &ircChatConnector{server: a.IRCServer, nick: nick, channel: a.IRCChannel, useTLS: a.IRCTLS}, nil
+	case "":
+		return nil, fmt.Errorf("action %q: chat requires \"protocol\"", name)
+	default:
+		return nil, fmt.Errorf("action %q: unknown chat protocol %q", name, a.Protocol)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// Slack / Discord / generic webhook
+// ---------------------------------------------------------------------------
+
+// webhookChatConnector POSTs a JSON body shaped for the given protocol's
+// incoming-webhook format. "generic" and "slack" both understand {"text":
+// ...}; Discord's incoming webhooks expect {"content": ...} instead.
+type webhookChatConnector struct {
+	url      string
+	protocol string
+}
+
+func (c *webhookChatConnector) send(ctx context.Context, title, body string) error {
+	text := title + "\n" + body
+	var payload []byte
+	var err error
+	if c.protocol == "discord" {
+		payload, err = json.Marshal(struct {
+			Content string `json:"content"`
+		}{Content: text})
+	} else {
+		payload, err = json.Marshal(struct {
+			Text string `json:"text"`
+		}{Text: text})
+	}
+	if err != nil {
+		return fmt.Errorf("chat/%s: marshal payload: %w", c.protocol, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("chat/%s: build request: %w", c.protocol, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("chat/%s: %w", c.protocol, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("chat/%s: %s returned %d", c.protocol, c.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// IRC
+// ---------------------------------------------------------------------------
+
+// ircConnectTimeout bounds how long ircChatConnector waits to dial, register,
+// and join before giving up on a single delivery.
+const ircConnectTimeout = 10 * time.Second
+
+// ircChatConnector delivers by opening a fresh connection per message,
+// registering as nick, joining channel, sending a PRIVMSG, and disconnecting.
+// That's wasteful for a chatty channel but matches process_watcher's other
+// actions in having no persistent background connection to manage or
+// reconnect.
+type ircChatConnector struct {
+	server  string
+	nick    string
+	channel string
+	useTLS  bool
+}
+
+func (c *ircChatConnector) send(ctx context.Context, title, body string) error {
+	dialer := net.Dialer{Timeout: ircConnectTimeout}
+	var conn net.Conn
+	var err error
+	if c.useTLS {
+		conn, err = tls.DialWithDialer(&dialer, "tcp", c.server, nil)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.server)
+	}
+	if err != nil {
+		return fmt.Errorf("irc: dial %s: %w", c.server, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ircConnectTimeout))
+
+	w := bufio.NewWriter(conn)
+	send := func(line string) error {
+		_, err := w.WriteString(line + "\r\n")
+		if err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+
+	if err := send(fmt.Sprintf("NICK %s", c.nick)); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	if err := send(fmt.Sprintf("USER %s 0 * :pnotify", c.nick)); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	if err := c.waitForWelcome(conn); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	if err := send(fmt.Sprintf("JOIN %s", c.channel)); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+
+	text := title + " — " + body
+	if err := send(fmt.Sprintf("PRIVMSG %s :%s", c.channel, text)); err != nil {
+		return fmt.Errorf("irc: %w", err)
+	}
+	return send("QUIT")
+}
+
+// waitForWelcome reads lines until the server's 001 (RPL_WELCOME) numeric,
+// replying to PING as needed so registration completes on ircd's that probe
+// before it.
+func (c *ircChatConnector) waitForWelcome(conn net.Conn) error {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case bytes.HasPrefix([]byte(line), []byte("PING")):
+			fmt.Fprintf(conn, "PONG%s\r\n", line[4:])
+		case bytes.Contains([]byte(line), []byte(" 001 ")):
+			return nil
+		}
+	}
+	return scanner.Err()
+}
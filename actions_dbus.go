@@ -0,0 +1,40 @@
+//go:build !freebsd
+
+// D-Bus notification delivery. Excluded on freebsd: godbus/dbus/v5's unix
+// transport doesn't implement the transport interface on that platform
+// (missing SendNullByte), so freebsd builds use actions_dbus_freebsd.go
+// instead.
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// dbusAction wraps the pre-action-plugin sendNotification call, so "dbus" is
+// just another entry in the registry rather than a special case.
+type dbusAction struct{}
+
+func (dbusAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	return sendNotification(msg.Title, msg.Body, msg.Urgency)
+}
+
+func sendNotification(title, body, urgency string) error {
+	conn, err := dbus.SessionBus()
+	if err != nil {
+		return fmt.Errorf("D-Bus session: %w", err)
+	}
+	urgencyMap := map[string]byte{"low": 0, "normal": 1, "critical": 2}
+	u, ok := urgencyMap[urgency]
+	if !ok {
+		u = 1
+	}
+	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(u)}
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	return obj.Call(
+		"org.freedesktop.Notifications.Notify", 0,
+		"process_watcher", uint32(0), "", title, body, []string{}, hints, int32(-1),
+	).Err
+}
@@ -0,0 +1,22 @@
+//go:build freebsd
+
+// godbus/dbus/v5's unix transport doesn't implement SendNullByte on
+// freebsd, so the kqueue backend's primary target platform gets a dbusAction
+// that reports its own unavailability instead of a build failure; criteria
+// that want delivery on freebsd should use webhook/exec/syslog/file/chat.
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+type dbusAction struct{}
+
+func (dbusAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	return sendNotification(msg.Title, msg.Body, msg.Urgency)
+}
+
+func sendNotification(title, body, urgency string) error {
+	return fmt.Errorf("dbus: not supported on freebsd (godbus/dbus/v5 has no working transport on this platform)")
+}
@@ -0,0 +1,74 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/syslog"
+)
+
+// syslogFacilities maps the facility names accepted in an action's "facility"
+// field to their syslog.Priority bits; syslog.LOG_USER is used when empty.
+var syslogFacilities = map[string]syslog.Priority{
+	"kern": syslog.LOG_KERN, "user": syslog.LOG_USER, "mail": syslog.LOG_MAIL,
+	"daemon": syslog.LOG_DAEMON, "auth": syslog.LOG_AUTH, "syslog": syslog.LOG_SYSLOG,
+	"local0": syslog.LOG_LOCAL0, "local1": syslog.LOG_LOCAL1, "local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3, "local4": syslog.LOG_LOCAL4, "local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6, "local7": syslog.LOG_LOCAL7,
+}
+
+// syslogSeverity maps a criterion's urgency to an RFC 5424 severity level.
+func syslogSeverity(urgency string) syslog.Priority {
+	switch urgency {
+	case "critical":
+		return syslog.LOG_CRIT
+	case "low":
+		return syslog.LOG_INFO
+	default:
+		return syslog.LOG_WARNING
+	}
+}
+
+// syslogAction writes one line per event via the stdlib log/syslog writer,
+// dialing network/address if given or the local syslog daemon otherwise.
+type syslogAction struct {
+	network  string
+	address  string
+	facility syslog.Priority
+	tag      string
+}
+
+func newSyslogAction(name string, a actionConfigRaw) (Action, error) {
+	facility := syslog.LOG_USER
+	if a.Facility != "" {
+		f, ok := syslogFacilities[a.Facility]
+		if !ok {
+			return nil, fmt.Errorf("action %q: unknown syslog facility %q", name, a.Facility)
+		}
+		facility = f
+	}
+	tag := a.Tag
+	if tag == "" {
+		tag = "pnotify"
+	}
+	return &syslogAction{network: a.Network, address: a.Address, facility: facility, tag: tag}, nil
+}
+
+func (a *syslogAction) Deliver(ctx context.Context, ev ProcEvent, msg RenderedMessage) error {
+	w, err := syslog.Dial(a.network, a.address, a.facility|syslogSeverity(msg.Urgency), a.tag)
+	if err != nil {
+		return fmt.Errorf("syslog: dial: %w", err)
+	}
+	defer w.Close()
+
+	line := fmt.Sprintf("[%s] %s | %s", ev.Kind, msg.Title, msg.Body)
+	switch msg.Urgency {
+	case "critical":
+		return w.Crit(line)
+	case "low":
+		return w.Info(line)
+	default:
+		return w.Warning(line)
+	}
+}
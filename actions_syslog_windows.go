@@ -0,0 +1,12 @@
+//go:build windows
+
+package main
+
+import "fmt"
+
+// newSyslogAction has no Windows implementation: the stdlib's log/syslog
+// only dials Unix-style syslog daemons. A config referencing a "syslog"
+// action fails to load rather than silently dropping deliveries.
+func newSyslogAction(name string, a actionConfigRaw) (Action, error) {
+	return nil, fmt.Errorf("action %q: syslog is not supported on windows", name)
+}
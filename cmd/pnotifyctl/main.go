@@ -0,0 +1,193 @@
+// pnotifyctl talks to a running process_watcher's gRPC control plane so
+// rules and match history can be inspected without editing config.json and
+// waiting for the fsnotify hot reload.
+//
+// Usage:
+//
+//	pnotifyctl tail
+//	pnotifyctl add -f rule.json
+//	pnotifyctl match --last 50
+//	pnotifyctl list
+//	pnotifyctl reload
+//	pnotifyctl rm <name>
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/valer-cara/pnotify/rpc/pnotifyv1"
+)
+
+func defaultSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return dir + "/pnotify.sock"
+}
+
+func dial(addr string) (pnotifyv1.WatcherClient, *grpc.ClientConn, error) {
+	target := addr
+	if after, ok := strings.CutPrefix(target, "tcp://"); ok {
+		target = after
+	} else if !strings.Contains(target, "://") {
+		target = "unix://" + target
+	}
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+	return pnotifyv1.NewWatcherClient(conn), conn, nil
+}
+
+func main() {
+	addr := flag.String("addr", defaultSocketPath(), "Control-plane address (unix socket path or tcp://host:port)")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: pnotifyctl <tail|add|match|list|reload|rm> [args]")
+		os.Exit(2)
+	}
+
+	client, conn, err := dial(*addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx := context.Background()
+	switch args[0] {
+	case "tail":
+		runTail(ctx, client, args[1:])
+	case "add":
+		runAdd(ctx, client, args[1:])
+	case "match":
+		runMatch(ctx, client, args[1:])
+	case "list":
+		runList(ctx, client)
+	case "reload":
+		runReload(ctx, client)
+	case "rm":
+		runRemove(ctx, client, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n", args[0])
+		os.Exit(2)
+	}
+}
+
+func runTail(ctx context.Context, client pnotifyv1.WatcherClient, args []string) {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	criterion := fs.String("criterion", "", "Only show matches for this criterion")
+	fs.Parse(args)
+
+	req := &pnotifyv1.SubscribeRequest{}
+	if *criterion != "" {
+		req.Criteria = []string{*criterion}
+	}
+	stream, err := client.Subscribe(ctx, req)
+	if err != nil {
+		log.Fatalf("subscribe: %v", err)
+	}
+	for {
+		ev, err := stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			log.Fatalf("subscribe: %v", err)
+		}
+		printMatch(ev)
+	}
+}
+
+func runAdd(ctx context.Context, client pnotifyv1.WatcherClient, args []string) {
+	fs := flag.NewFlagSet("add", flag.ExitOnError)
+	file := fs.String("f", "", "Path to a criterionRaw JSON file")
+	fs.Parse(args)
+
+	if *file == "" {
+		log.Fatal("add: -f is required")
+	}
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		log.Fatalf("add: %v", err)
+	}
+
+	resp, err := client.AddCriterion(ctx, &pnotifyv1.AddCriterionRequest{CriterionJson: data})
+	if err != nil {
+		log.Fatalf("add: %v", err)
+	}
+	if !resp.GetOk() {
+		log.Fatalf("add rejected: %s", resp.GetError())
+	}
+	fmt.Println("criterion added")
+}
+
+func runMatch(ctx context.Context, client pnotifyv1.WatcherClient, args []string) {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	last := fs.Int("last", 50, "Number of recent matches to show")
+	fs.Parse(args)
+
+	resp, err := client.RecentMatches(ctx, &pnotifyv1.RecentMatchesRequest{Limit: int32(*last)})
+	if err != nil {
+		log.Fatalf("match: %v", err)
+	}
+	for _, m := range resp.GetMatches() {
+		printMatch(m)
+	}
+}
+
+func runList(ctx context.Context, client pnotifyv1.WatcherClient) {
+	resp, err := client.ListCriteria(ctx, &pnotifyv1.ListCriteriaRequest{})
+	if err != nil {
+		log.Fatalf("list: %v", err)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	for _, c := range resp.GetCriteria() {
+		enc.Encode(c)
+	}
+}
+
+func runReload(ctx context.Context, client pnotifyv1.WatcherClient) {
+	resp, err := client.ReloadConfig(ctx, &pnotifyv1.ReloadConfigRequest{})
+	if err != nil {
+		log.Fatalf("reload: %v", err)
+	}
+	if !resp.GetOk() {
+		log.Fatalf("reload failed: %s", resp.GetError())
+	}
+	fmt.Printf("config reloaded: %d criteria\n", resp.GetCriteriaCount())
+}
+
+func runRemove(ctx context.Context, client pnotifyv1.WatcherClient, args []string) {
+	if len(args) != 1 {
+		log.Fatal("usage: pnotifyctl rm <name>")
+	}
+	resp, err := client.RemoveCriterion(ctx, &pnotifyv1.RemoveCriterionRequest{Name: args[0]})
+	if err != nil {
+		log.Fatalf("rm: %v", err)
+	}
+	if !resp.GetRemoved() {
+		log.Fatalf("no criterion named %q", args[0])
+	}
+	fmt.Println("removed")
+}
+
+func printMatch(m *pnotifyv1.MatchEvent) {
+	ts := time.Unix(m.GetTimestampUnix(), 0).Format(time.RFC3339)
+	fmt.Printf("%s [%s] (%s) pid=%d ppid=%d — %s | %s\n",
+		ts, m.GetCriterion(), m.GetEventKind(), m.GetPid(), m.GetPpid(), m.GetTitle(), m.GetBody())
+}
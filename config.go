@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// configFile is the object form of config.json introduced alongside the
+// action plugin system: "criteria" plus a top-level "actions" section that
+// criteria reference by name. The legacy bare array of criteria (no named
+// actions, implicitly D-Bus-only) is still accepted — see parseConfigFile.
+type configFile struct {
+	Criteria []criterionRaw             `json:"criteria"`
+	Actions  map[string]actionConfigRaw `json:"actions"`
+}
+
+// actionConfigRaw is one entry in the top-level "actions" config section,
+// keyed by the name criteria reference in their own "actions" list. Only the
+// fields relevant to Type are read; the rest are ignored.
+type actionConfigRaw struct {
+	Type string `json:"type"`
+
+	// webhook
+	URL        string `json:"url"`
+	Secret     string `json:"secret"`
+	MaxRetries int    `json:"max_retries"`
+
+	// exec
+	Command []string `json:"command"`
+	Timeout string   `json:"timeout"`
+
+	// syslog
+	Network  string `json:"network"`
+	Address  string `json:"address"`
+	Facility string `json:"facility"`
+	Tag      string `json:"tag"`
+
+	// file
+	Path       string `json:"path"`
+	MaxSizeMB  int    `json:"max_size_mb"`
+	MaxBackups int    `json:"max_backups"`
+
+	// chat — one connector per protocol, matterbridge-style
+	Protocol   string `json:"protocol"`
+	IRCServer  string `json:"irc_server"`
+	IRCNick    string `json:"irc_nick"`
+	IRCChannel string `json:"irc_channel"`
+	IRCTLS     bool   `json:"irc_tls"`
+}
+
+// parseConfigFile accepts both the pre-action-plugin bare JSON array of
+// criteria and the new object form with "criteria"/"actions" keys, telling
+// them apart by the first non-whitespace byte.
+func parseConfigFile(data []byte) ([]criterionRaw, map[string]actionConfigRaw, error) {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 {
+		return nil, nil, fmt.Errorf("empty config")
+	}
+	if trimmed[0] == '[' {
+		var raw []criterionRaw
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, nil, err
+		}
+		return raw, nil, nil
+	}
+	var cf configFile
+	if err := json.Unmarshal(data, &cf); err != nil {
+		return nil, nil, err
+	}
+	return cf.Criteria, cf.Actions, nil
+}
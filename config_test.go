@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFileLegacyArray(t *testing.T) {
+	data := []byte(`[{"name":"c1","notify_title":"t"},{"name":"c2","notify_title":"t2"}]`)
+	criteria, actions, err := parseConfigFile(data)
+	if err != nil {
+		t.Fatalf("parseConfigFile: %v", err)
+	}
+	if len(criteria) != 2 {
+		t.Fatalf("len(criteria) = %d, want 2", len(criteria))
+	}
+	if criteria[0].Name != "c1" || criteria[1].Name != "c2" {
+		t.Errorf("criteria = %+v, want names c1/c2", criteria)
+	}
+	if actions != nil {
+		t.Errorf("actions = %+v, want nil for the legacy bare-array form", actions)
+	}
+}
+
+func TestParseConfigFileObjectForm(t *testing.T) {
+	data := []byte(`{
+		"criteria": [{"name":"c1"}],
+		"actions": {"pagerduty": {"type":"webhook","url":"https://example.com"}}
+	}`)
+	criteria, actions, err := parseConfigFile(data)
+	if err != nil {
+		t.Fatalf("parseConfigFile: %v", err)
+	}
+	if len(criteria) != 1 || criteria[0].Name != "c1" {
+		t.Errorf("criteria = %+v, want one entry named c1", criteria)
+	}
+	act, ok := actions["pagerduty"]
+	if !ok {
+		t.Fatalf("actions missing %q key", "pagerduty")
+	}
+	if act.Type != "webhook" || act.URL != "https://example.com" {
+		t.Errorf("actions[\"pagerduty\"] = %+v", act)
+	}
+}
+
+func TestParseConfigFileWhitespaceTolerant(t *testing.T) {
+	data := []byte("  \n\t [{\"name\":\"c1\"}]")
+	criteria, _, err := parseConfigFile(data)
+	if err != nil {
+		t.Fatalf("parseConfigFile: %v", err)
+	}
+	if len(criteria) != 1 {
+		t.Fatalf("len(criteria) = %d, want 1", len(criteria))
+	}
+}
+
+func TestParseConfigFileEmpty(t *testing.T) {
+	if _, _, err := parseConfigFile([]byte("   ")); err == nil {
+		t.Error("parseConfigFile on blank input should error")
+	}
+}
+
+func TestParseConfigFileInvalidJSON(t *testing.T) {
+	if _, _, err := parseConfigFile([]byte("[not json")); err == nil {
+		t.Error("parseConfigFile on malformed array should error")
+	}
+	if _, _, err := parseConfigFile([]byte("{not json")); err == nil {
+		t.Error("parseConfigFile on malformed object should error")
+	}
+}
+
+func TestWarnUnsupportedEventsSkipsSupportedKinds(t *testing.T) {
+	criteria, err := buildCriteria([]criterionRaw{{
+		Name:  "c1",
+		Match: criterionMatch{On: []string{EventExec, EventCoredump}},
+	}})
+	if err != nil {
+		t.Fatalf("buildCriteria: %v", err)
+	}
+	caps := SourceCaps{Events: []string{EventExec, EventFork, EventExit}}
+	if caps.supports(EventCoredump) {
+		t.Fatalf("caps unexpectedly supports %q", EventCoredump)
+	}
+	// warnUnsupportedEvents only logs, so capture the log to check that it
+	// warns about the unsupported kind and stays quiet about the supported one.
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	warnUnsupportedEvents("kqueue", caps, criteria)
+	out := buf.String()
+	if !strings.Contains(out, `event kind "coredump"`) {
+		t.Errorf("log = %q, want a warning for %q", out, EventCoredump)
+	}
+	if strings.Contains(out, `event kind "exec"`) {
+		t.Errorf("log = %q, want no warning for supported %q", out, EventExec)
+	}
+}
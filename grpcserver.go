@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/valer-cara/pnotify/rpc/pnotifyv1"
+)
+
+// grpcTLSConfig carries the optional mTLS material for a TCP control-plane
+// listener; it is the zero value (no TLS) for the default unix socket.
+type grpcTLSConfig struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+func (t grpcTLSConfig) enabled() bool {
+	return t.CertFile != "" || t.KeyFile != "" || t.CAFile != ""
+}
+
+// defaultGRPCSocketPath mirrors $XDG_RUNTIME_DIR/pnotify.sock, falling back
+// to /tmp when the invoking session has no runtime dir (e.g. a system unit).
+func defaultGRPCSocketPath() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return dir + "/pnotify.sock"
+}
+
+// startGRPCServer brings up the pnotify.v1.Watcher control plane on listen,
+// which is either a bare path (unix socket, chmod 0600) or a "tcp://host:port"
+// URL (optionally mTLS-protected via tlsCfg). It runs until ctx is canceled.
+func startGRPCServer(ctx context.Context, w *ProcessWatcher, listen string, tlsCfg grpcTLSConfig) error {
+	var (
+		lis     net.Listener
+		err     error
+		opts    []grpc.ServerOption
+		network string
+		addr    string
+	)
+
+	if after, ok := strings.CutPrefix(listen, "tcp://"); ok {
+		network, addr = "tcp", after
+	} else {
+		network, addr = "unix", listen
+		_ = os.Remove(addr) // stale socket from a previous run
+	}
+
+	if network == "tcp" && tlsCfg.enabled() {
+		creds, err := loadServerTLS(tlsCfg)
+		if err != nil {
+			return fmt.Errorf("grpc TLS: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	lis, err = net.Listen(network, addr)
+	if err != nil {
+		return fmt.Errorf("grpc listen %s: %w", listen, err)
+	}
+	if network == "unix" {
+		if err := os.Chmod(addr, 0o600); err != nil {
+			log.Printf("Warning: could not chmod %s to 0600: %v", addr, err)
+		}
+	}
+
+	srv := grpc.NewServer(opts...)
+	pnotifyv1.RegisterWatcherServer(srv, &watcherServer{w: w})
+
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+
+	log.Printf("gRPC control plane listening on %s://%s", network, addr)
+	return srv.Serve(lis)
+}
+
+func loadServerTLS(cfg grpcTLSConfig) (credentials.TransportCredentials, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load cert/key: %w", err)
+	}
+	tlsConf := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if cfg.CAFile != "" {
+		caBytes, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBytes) {
+			return nil, fmt.Errorf("no certificates parsed from %s", cfg.CAFile)
+		}
+		tlsConf.ClientCAs = pool
+		tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return credentials.NewTLS(tlsConf), nil
+}
+
+// watcherServer implements pnotifyv1.WatcherServer against a ProcessWatcher.
+type watcherServer struct {
+	pnotifyv1.UnimplementedWatcherServer
+	w *ProcessWatcher
+}
+
+func (s *watcherServer) ListCriteria(ctx context.Context, req *pnotifyv1.ListCriteriaRequest) (*pnotifyv1.ListCriteriaResponse, error) {
+	criteria := s.w.listCriteria()
+	out := make([]*pnotifyv1.CriterionState, len(criteria))
+	for i, c := range criteria {
+		// TokensRemaining/SuppressedCount stay zero for a criterion with no
+		// throttle block configured.
+		tokens, suppressed := s.w.throttler.snapshot(c)
+		out[i] = &pnotifyv1.CriterionState{
+			Name:            c.Name,
+			NotifyTitle:     c.notifyTitle,
+			NotifyBody:      c.notifyBody,
+			Urgency:         c.urgency,
+			TokensRemaining: int32(tokens),
+			SuppressedCount: int32(suppressed),
+		}
+	}
+	return &pnotifyv1.ListCriteriaResponse{Criteria: out}, nil
+}
+
+func (s *watcherServer) AddCriterion(ctx context.Context, req *pnotifyv1.AddCriterionRequest) (*pnotifyv1.AddCriterionResponse, error) {
+	var raw criterionRaw
+	if err := json.Unmarshal(req.GetCriterionJson(), &raw); err != nil {
+		return &pnotifyv1.AddCriterionResponse{Ok: false, Error: err.Error()}, nil
+	}
+	if err := s.w.addCriterion(raw); err != nil {
+		return &pnotifyv1.AddCriterionResponse{Ok: false, Error: err.Error()}, nil
+	}
+	return &pnotifyv1.AddCriterionResponse{Ok: true}, nil
+}
+
+func (s *watcherServer) RemoveCriterion(ctx context.Context, req *pnotifyv1.RemoveCriterionRequest) (*pnotifyv1.RemoveCriterionResponse, error) {
+	return &pnotifyv1.RemoveCriterionResponse{Removed: s.w.removeCriterion(req.GetName())}, nil
+}
+
+func (s *watcherServer) ReloadConfig(ctx context.Context, req *pnotifyv1.ReloadConfigRequest) (*pnotifyv1.ReloadConfigResponse, error) {
+	s.w.reloadConfig()
+	return &pnotifyv1.ReloadConfigResponse{Ok: true, CriteriaCount: int32(len(s.w.listCriteria()))}, nil
+}
+
+func (s *watcherServer) RecentMatches(ctx context.Context, req *pnotifyv1.RecentMatchesRequest) (*pnotifyv1.RecentMatchesResponse, error) {
+	matches := s.w.recentMatches(int(req.GetLimit()))
+	out := make([]*pnotifyv1.MatchEvent, len(matches))
+	for i, m := range matches {
+		out[i] = toMatchEventProto(m)
+	}
+	return &pnotifyv1.RecentMatchesResponse{Matches: out}, nil
+}
+
+func (s *watcherServer) Subscribe(req *pnotifyv1.SubscribeRequest, stream pnotifyv1.Watcher_SubscribeServer) error {
+	wanted := make(map[string]bool, len(req.GetCriteria()))
+	for _, name := range req.GetCriteria() {
+		wanted[name] = true
+	}
+
+	ch, cancel := s.w.subscribeMatches()
+	defer cancel()
+
+	for {
+		select {
+		case m, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if len(wanted) > 0 && !wanted[m.Criterion] {
+				continue
+			}
+			if err := stream.Send(toMatchEventProto(m)); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		}
+	}
+}
+
+func toMatchEventProto(m Match) *pnotifyv1.MatchEvent {
+	ts := m.Event.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	return &pnotifyv1.MatchEvent{
+		Criterion:     m.Criterion,
+		EventKind:     m.Event.Kind,
+		Pid:           m.Event.Pid,
+		Ppid:          m.Event.ParentPid,
+		Title:         m.Title,
+		Body:          m.Body,
+		TimestampUnix: ts.Unix(),
+	}
+}
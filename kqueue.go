@@ -0,0 +1,153 @@
+//go:build darwin || freebsd || openbsd
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// kqueueRescanInterval is how often kqueueSource re-sweeps /proc (or the platform
+// equivalent via gopsutil) for pids the kernel never announced — e.g. ones
+// that existed before Subscribe was called, or a fork whose NOTE_TRACK
+// child-pid delivery raced our EV_ADD for the child itself.
+const kqueueRescanInterval = 5 * time.Second
+
+// kqueueSource is the FreeBSD/OpenBSD/macOS ProcessSource. It watches every
+// known pid with EVFILT_PROC and NOTE_EXEC|NOTE_FORK|NOTE_EXIT|NOTE_TRACK,
+// discovering new pids from an initial process.Pids() sweep plus a periodic
+// re-scan, the same two-phase strategy rjeczalik/notify uses to fall back
+// across inotify/kqueue/FSEvents per platform.
+type kqueueSource struct{}
+
+func newKqueueSource() *kqueueSource { return &kqueueSource{} }
+
+func (s *kqueueSource) Name() string { return "kqueue" }
+
+func (s *kqueueSource) Capabilities() SourceCaps {
+	// EVFILT_PROC has no notion of uid/gid changes, comm renames, or
+	// coredumps — those are CN_PROC-only until/unless an ES-backed source
+	// is added.
+	return SourceCaps{Events: []string{EventExec, EventFork, EventExit}, Live: true}
+}
+
+func (s *kqueueSource) Subscribe(ctx context.Context) (<-chan ProcEvent, error) {
+	kq, err := syscall.Kqueue()
+	if err != nil {
+		return nil, fmt.Errorf("kqueue: %w", err)
+	}
+
+	var mu sync.Mutex
+	watched := make(map[int32]struct{})
+
+	watch := func(pid int32) {
+		mu.Lock()
+		_, already := watched[pid]
+		mu.Unlock()
+		if already {
+			return
+		}
+		kev := syscall.Kevent_t{
+			Ident:  uint64(pid),
+			Filter: syscall.EVFILT_PROC,
+			Flags:  syscall.EV_ADD | syscall.EV_CLEAR,
+			Fflags: syscall.NOTE_EXEC | syscall.NOTE_FORK | syscall.NOTE_EXIT | syscall.NOTE_TRACK,
+		}
+		if _, err := syscall.Kevent(kq, []syscall.Kevent_t{kev}, nil, nil); err != nil {
+			return // pid exited between the sweep and EV_ADD; ignore
+		}
+		mu.Lock()
+		watched[pid] = struct{}{}
+		mu.Unlock()
+	}
+
+	sweep := func() {
+		pids, _ := process.Pids()
+		for _, pid := range pids {
+			watch(pid)
+		}
+	}
+	sweep()
+
+	ch := make(chan ProcEvent, 256)
+	go func() {
+		defer syscall.Close(kq)
+		defer close(ch)
+
+		rescan := time.NewTicker(kqueueRescanInterval)
+		defer rescan.Stop()
+
+		events := make([]syscall.Kevent_t, 64)
+		timeout := syscall.NsecToTimespec(int64(time.Second))
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-rescan.C:
+				sweep()
+			default:
+			}
+
+			n, err := syscall.Kevent(kq, nil, events, &timeout)
+			if err != nil {
+				if err == syscall.EINTR {
+					continue
+				}
+				log.Printf("kqueue read error: %v", err)
+				return
+			}
+
+			for _, kev := range events[:n] {
+				pid := int32(kev.Ident)
+				var kind string
+				var parentPid int32
+
+				switch {
+				case kev.Fflags&syscall.NOTE_EXIT != 0:
+					kind = EventExit
+					mu.Lock()
+					delete(watched, pid)
+					mu.Unlock()
+				case kev.Fflags&syscall.NOTE_CHILD != 0:
+					// NOTE_TRACK on the parent makes the kernel auto-attach a
+					// knote for the child and deliver NOTE_CHILD on it: Ident
+					// is the child pid, Data the forking parent's pid. This is
+					// the only one of the two fork-related flags that actually
+					// names the child; the plain NOTE_FORK below fires on the
+					// parent's own knote and never carries a child pid.
+					kind = EventFork
+					parentPid = int32(kev.Data)
+					mu.Lock()
+					watched[pid] = struct{}{}
+					mu.Unlock()
+				case kev.Fflags&syscall.NOTE_FORK != 0:
+					// No child pid to report here; wait for NOTE_CHILD.
+					continue
+				case kev.Fflags&syscall.NOTE_EXEC != 0:
+					kind = EventExec
+				default:
+					continue
+				}
+
+				ev := ProcEvent{Kind: kind, Pid: pid, ParentPid: parentPid, Timestamp: time.Now()}
+				select {
+				case ch <- ev:
+				default:
+					log.Printf("kqueue: event channel full, dropping %s event for pid %d", kind, pid)
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// nativeSource returns this platform's kernel-backed ProcessSource.
+func nativeSource() ProcessSource { return newKqueueSource() }
@@ -0,0 +1,293 @@
+// Process lineage tracking — a live pid -> {ppid, name, exe, startTime}
+// tree fed by the fork/exec/exit events every ProcessSource delivers. It
+// backs ancestor_name_regex/parent_cmdline_contains/depth_from matching and
+// the {parent_name}/{parent_cmdline}/{ancestry} template placeholders.
+package main
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// processTreeSizeLimit is the default cap on the number of tracked pids, so
+// a fork-heavy host doesn't grow the tree without bound; -lineage-max-pids
+// overrides it.
+const processTreeSizeLimit = 20000
+
+// lineageEntry is one process's place in the ancestry tree.
+type lineageEntry struct {
+	Ppid            int32
+	Name            string
+	Exe             string
+	Cmdline         string
+	Username        string
+	StartTime       time.Time
+	CriteriaMatched []string
+}
+
+// processTree is seeded once at startup by walking every running process,
+// then kept live by observe() as fork/exec/exit events arrive (or, under the
+// polling source, their synthesized equivalents).
+type processTree struct {
+	maxSize int
+
+	mu      sync.RWMutex
+	entries map[int32]*lineageEntry
+
+	evictWarnOnce sync.Once
+}
+
+func newProcessTree(maxSize int) *processTree {
+	if maxSize <= 0 {
+		maxSize = processTreeSizeLimit
+	}
+	return &processTree{maxSize: maxSize, entries: make(map[int32]*lineageEntry)}
+}
+
+// seed walks every currently running process once, so ancestry lookups work
+// immediately rather than only covering processes started after the watcher.
+func (t *processTree) seed() {
+	procs, err := process.Processes()
+	if err != nil {
+		log.Printf("processTree: seed failed: %v", err)
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range procs {
+		t.insertLocked(p.Pid, liveEntry(p))
+	}
+}
+
+func liveEntry(p *process.Process) *lineageEntry {
+	ppid, _ := p.Ppid()
+	name, _ := p.Name()
+	exe, _ := p.Exe()
+	parts, _ := p.CmdlineSlice()
+	username, _ := p.Username()
+	entry := &lineageEntry{Ppid: ppid, Name: name, Exe: exe, Cmdline: strings.Join(parts, " "), Username: username}
+	if createMs, err := p.CreateTime(); err == nil {
+		entry.StartTime = time.UnixMilli(createMs)
+	}
+	return entry
+}
+
+// size reports how many pids are currently tracked.
+func (t *processTree) size() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.entries)
+}
+
+// observe updates the tree from one process-lifecycle event: fork and exec
+// both (re-)resolve the live process so name/exe reflect whatever is running
+// after the event; exit evicts instead. A netlink exec event carries no
+// parent pid of its own (ev.ParentPid == 0), so the entry's Ppid is only
+// overwritten when the event actually supplies one; otherwise whatever fork
+// already established is preserved, falling back to a live lookup the first
+// time a pid is observed without one.
+func (t *processTree) observe(ev ProcEvent, proc *process.Process) {
+	if ev.Kind == EventExit {
+		t.remove(ev.Pid)
+		return
+	}
+
+	entry := &lineageEntry{StartTime: ev.Timestamp}
+	if proc != nil {
+		name, _ := proc.Name()
+		exe, _ := proc.Exe()
+		parts, _ := proc.CmdlineSlice()
+		username, _ := proc.Username()
+		entry.Name = name
+		entry.Exe = exe
+		entry.Cmdline = strings.Join(parts, " ")
+		entry.Username = username
+		if createMs, err := proc.CreateTime(); err == nil {
+			entry.StartTime = time.UnixMilli(createMs)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	existing, hadExisting := t.entries[ev.Pid]
+	if hadExisting {
+		entry.CriteriaMatched = existing.CriteriaMatched
+	}
+	switch {
+	case ev.ParentPid != 0:
+		entry.Ppid = ev.ParentPid
+	case hadExisting:
+		entry.Ppid = existing.Ppid
+	case proc != nil:
+		entry.Ppid, _ = proc.Ppid()
+	}
+	t.insertLocked(ev.Pid, entry)
+}
+
+// insertLocked evicts the oldest tracked pid first if adding pid would push
+// the tree past maxSize. Callers must hold t.mu.
+func (t *processTree) insertLocked(pid int32, entry *lineageEntry) {
+	if _, exists := t.entries[pid]; !exists && len(t.entries) >= t.maxSize {
+		t.evictOldestLocked()
+	}
+	t.entries[pid] = entry
+}
+
+func (t *processTree) evictOldestLocked() {
+	var oldestPid int32
+	var oldestAt time.Time
+	found := false
+	for pid, e := range t.entries {
+		if !found || e.StartTime.Before(oldestAt) {
+			oldestPid, oldestAt, found = pid, e.StartTime, true
+		}
+	}
+	if !found {
+		return
+	}
+	delete(t.entries, oldestPid)
+	t.evictWarnOnce.Do(func() {
+		log.Printf("processTree: size limit (%d pids) reached, evicting oldest entries to make room", t.maxSize)
+	})
+}
+
+// identity returns the last-known name/cmdline/username the tree recorded for
+// pid (as of its most recent fork/exec), for matching against an exit event
+// whose own process is already gone. ok is false if pid was never tracked.
+func (t *processTree) identity(pid int32) (name, cmdline, username string, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	e, found := t.entries[pid]
+	if !found {
+		return "", "", "", false
+	}
+	return e.Name, e.Cmdline, e.Username, true
+}
+
+// ppid returns the last-known parent pid the tree recorded for pid, for
+// resolving events (like a netlink exec) that don't carry their own parent.
+func (t *processTree) ppid(pid int32) (int32, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	e, found := t.entries[pid]
+	if !found {
+		return 0, false
+	}
+	return e.Ppid, true
+}
+
+func (t *processTree) remove(pid int32) {
+	t.mu.Lock()
+	delete(t.entries, pid)
+	t.mu.Unlock()
+}
+
+// recordMatch appends criterionName to pid's match history, if pid is still
+// tracked.
+func (t *processTree) recordMatch(pid int32, criterionName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.entries[pid]; ok {
+		e.CriteriaMatched = append(e.CriteriaMatched, criterionName)
+	}
+}
+
+// ancestors returns the names of the ancestors starting at startPpid and
+// walking up through each ancestor's own Ppid, stopping at init (pid 1), an
+// untracked pid, or after maxHops names (0 = unbounded). It takes the
+// starting pid directly rather than looking up a child's own entry, so it
+// works even for a pid the tree hasn't recorded yet (e.g. mid-fork/exec).
+func (t *processTree) ancestors(startPpid int32, maxHops int) []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var names []string
+	cur := startPpid
+	for maxHops <= 0 || len(names) < maxHops {
+		e, ok := t.entries[cur]
+		if !ok {
+			break
+		}
+		names = append(names, e.Name)
+		if cur == 1 || e.Ppid == 0 || e.Ppid == cur {
+			break
+		}
+		cur = e.Ppid
+	}
+	return names
+}
+
+// ancestryString renders the full ancestor chain outermost-first, e.g.
+// "systemd→sshd→bash→curl", with selfName appended last.
+func (t *processTree) ancestryString(startPpid int32, selfName string) string {
+	up := t.ancestors(startPpid, 0)
+	chain := make([]string, 0, len(up)+1)
+	for i := len(up) - 1; i >= 0; i-- {
+		chain = append(chain, up[i])
+	}
+	if selfName != "" {
+		chain = append(chain, selfName)
+	}
+	return strings.Join(chain, "→")
+}
+
+// hasAncestorMatching reports whether any ancestor within maxHops hops of
+// startPpid (0 = unbounded, all the way to init) has a name matching re.
+func (t *processTree) hasAncestorMatching(startPpid int32, re *regexp.Regexp, maxHops int) bool {
+	for _, name := range t.ancestors(startPpid, maxHops) {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdline returns the last-known cmdline the tree recorded for pid.
+func (t *processTree) cmdline(pid int32) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	e, found := t.entries[pid]
+	if !found {
+		return "", false
+	}
+	return e.Cmdline, true
+}
+
+// parentCmdlineContains checks ppid's cmdline for every term, preferring a
+// live gopsutil lookup but falling back to tree's cached cmdline (set at
+// ppid's own fork/exec) when ppid has already exited by the time its child's
+// event is observed.
+func parentCmdlineContains(ppid int32, terms []string, tree *processTree) bool {
+	if len(terms) == 0 {
+		return true
+	}
+	cmdline, ok := liveCmdline(ppid)
+	if !ok && tree != nil {
+		cmdline, ok = tree.cmdline(ppid)
+	}
+	if !ok {
+		return false
+	}
+	for _, term := range terms {
+		if !strings.Contains(cmdline, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// liveCmdline fetches pid's cmdline directly from the OS, without touching
+// the tree.
+func liveCmdline(pid int32) (string, bool) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		return "", false
+	}
+	parts, _ := proc.CmdlineSlice()
+	return strings.Join(parts, " "), true
+}
@@ -1,5 +1,6 @@
 // process_watcher — watches for new processes matching configurable criteria
-// and sends desktop notifications via D-Bus (org.freedesktop.Notifications).
+// and delivers notifications through one or more pluggable Actions (D-Bus by
+// default; see actions.go for webhook, exec, syslog, file, and chat delivery).
 //
 // Build:
 //
@@ -9,11 +10,11 @@
 //
 //	./notifier                          # uses ./config.json
 //	./notifier -config criteria.json
+//	./notifier -source kqueue           # force a specific event backend
 package main
 
 import (
 	"context"
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -21,60 +22,207 @@ import (
 	"os/signal"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
-	"github.com/godbus/dbus/v5"
 	"github.com/shirou/gopsutil/v3/process"
 )
 
 const fallbackPollInterval = 2 * time.Second
 
+// ---------------------------------------------------------------------------
+// Process events
+// ---------------------------------------------------------------------------
+
+// Event kinds a criterion can subscribe to via criterionMatch.On. These
+// mirror the event family CN_PROC multiplexes onto a single netlink socket.
+const (
+	EventExec     = "exec"
+	EventFork     = "fork"
+	EventExit     = "exit"
+	EventSetuid   = "setuid"
+	EventSetgid   = "setgid"
+	EventComm     = "comm"
+	EventCoredump = "coredump"
+)
+
+// ProcEvent is a single process-lifecycle event, whether sourced from the
+// Linux netlink proc connector or synthesized by the polling fallback.
+type ProcEvent struct {
+	Kind       string
+	Pid        int32
+	Tgid       int32
+	ParentPid  int32
+	ParentTgid int32
+	ExitCode   int32
+	ExitSignal int32
+	RUID       uint32
+	EUID       uint32
+	RGID       uint32
+	EGID       uint32
+	Timestamp  time.Time
+}
+
 // ---------------------------------------------------------------------------
 // Config JSON structures
 // ---------------------------------------------------------------------------
 
 type criterionRaw struct {
-	Name        string        `json:"name"`
+	Name        string         `json:"name"`
 	Match       criterionMatch `json:"match"`
-	NotifyTitle string        `json:"notify_title"`
-	NotifyBody  string        `json:"notify_body"`
-	Urgency     string        `json:"urgency"`
+	NotifyTitle string         `json:"notify_title"`
+	NotifyBody  string         `json:"notify_body"`
+	// NotifyBodyCoalesced renders the body of a single notification merged
+	// from several matches inside a throttle.coalesce_window, with {count}
+	// and {pids} in addition to the usual placeholders.
+	NotifyBodyCoalesced string `json:"notify_body_coalesced"`
+	Urgency             string `json:"urgency"`
+	// Actions names delivery targets defined in the top-level "actions"
+	// config section, e.g. ["dbus", "webhook:pagerduty", "exec:local"]. An
+	// empty list falls back to D-Bus-only delivery.
+	Actions []string `json:"actions"`
+	// Throttle bounds how often this criterion is actually delivered; see
+	// throttle.go. A nil Throttle delivers every match, unchanged.
+	Throttle *throttleRaw `json:"throttle"`
+}
+
+// throttleRaw is the JSON shape of a criterion's "throttle" block.
+type throttleRaw struct {
+	MaxPerMinute   int      `json:"max_per_minute"`
+	CoalesceWindow string   `json:"coalesce_window"`
+	CooldownAfter  string   `json:"cooldown_after"`
+	QuietHours     []string `json:"quiet_hours"`
 }
 
 type criterionMatch struct {
+	On              []string `json:"on"`
 	NameRegex       string   `json:"name_regex"`
 	CmdlineContains []string `json:"cmdline_contains"`
 	Username        string   `json:"username"`
+	ExitCode        *int     `json:"exit_code"`
+	Signal          string   `json:"signal"`
+	ParentNameRegex string   `json:"parent_name_regex"`
+	// AncestorNameRegex matches any ancestor up to init, not just the
+	// immediate parent; DepthFrom bounds how many hops up that search goes
+	// (0 = unbounded), e.g. "only match if there's a bash ancestor within 3
+	// hops" for spotting reverse shells.
+	AncestorNameRegex     string         `json:"ancestor_name_regex"`
+	ParentCmdlineContains []string       `json:"parent_cmdline_contains"`
+	DepthFrom             int            `json:"depth_from"`
+	BecameRoot            bool           `json:"became_root"`
+	Resource              *resourceMatch `json:"resource"`
+}
+
+// resourceMatch thresholds a criterion's process against periodic
+// cgroup/procfs sampling rather than a single event. A criterion with a
+// resource block is tracked continuously by the resourceSampler once its
+// other match fields first identify a pid (see matchesIdentity), and fires
+// once the configured metric has been over threshold for SustainedFor
+// consecutive samples.
+type resourceMatch struct {
+	CPUPercentOver     *float64 `json:"cpu_percent_over"`
+	RSSMBOver          *float64 `json:"rss_mb_over"`
+	OpenFDsOver        *int     `json:"open_fds_over"`
+	IOReadMBPerSecOver *float64 `json:"io_read_mb_per_sec_over"`
+	SustainedFor       int      `json:"sustained_for"`
 }
 
 // ---------------------------------------------------------------------------
 // Criterion
 // ---------------------------------------------------------------------------
 
+// signalNumbers maps the signal names accepted in criterionMatch.Signal
+// ("SIGSEGV", "segv", or a bare number) to their numeric value.
+var signalNumbers = map[string]int{
+	"sighup": 1, "sigint": 2, "sigquit": 3, "sigill": 4, "sigtrap": 5,
+	"sigabrt": 6, "sigbus": 7, "sigfpe": 8, "sigkill": 9, "sigusr1": 10,
+	"sigsegv": 11, "sigusr2": 12, "sigpipe": 13, "sigalrm": 14, "sigterm": 15,
+}
+
+func signalNumber(name string) (int, bool) {
+	if n, err := strconv.Atoi(name); err == nil {
+		return n, true
+	}
+	key := strings.ToLower(name)
+	if !strings.HasPrefix(key, "sig") {
+		key = "sig" + key
+	}
+	n, ok := signalNumbers[key]
+	return n, ok
+}
+
 type Criterion struct {
-	Name            string
-	nameRegex       *regexp.Regexp
-	cmdlineContains []string
-	username        string
-	notifyTitle     string
-	notifyBody      string
-	urgency         string
+	Name                  string
+	on                    map[string]bool
+	nameRegex             *regexp.Regexp
+	cmdlineContains       []string
+	username              string
+	exitCode              *int
+	signal                int
+	parentNameRegex       *regexp.Regexp
+	ancestorNameRegex     *regexp.Regexp
+	parentCmdlineContains []string
+	depthFrom             int
+	becameRoot            bool
+	resource              *resourceMatch
+	actionNames           []string
+	throttle              *criterionThrottle
+	notifyTitle           string
+	notifyBody            string
+	notifyBodyCoalesced   string
+	urgency               string
+}
+
+// wantsEvent reports whether the criterion subscribes to the given event
+// kind. With no "on" list configured, a criterion only fires on exec, which
+// matches the original exec-only behavior.
+func (c *Criterion) wantsEvent(kind string) bool {
+	if len(c.on) == 0 {
+		return kind == EventExec
+	}
+	return c.on[kind]
+}
+
+// eventKinds returns the event kinds c actually subscribes to, applying the
+// same "no on list means exec-only" default as wantsEvent.
+func (c *Criterion) eventKinds() []string {
+	if len(c.on) == 0 {
+		return []string{EventExec}
+	}
+	kinds := make([]string, 0, len(c.on))
+	for kind := range c.on {
+		kinds = append(kinds, kind)
+	}
+	return kinds
 }
 
 func buildCriteria(raw []criterionRaw) ([]*Criterion, error) {
 	out := make([]*Criterion, 0, len(raw))
 	for _, r := range raw {
 		c := &Criterion{
-			Name:            r.Name,
-			cmdlineContains: r.Match.CmdlineContains,
-			username:        r.Match.Username,
-			notifyTitle:     r.NotifyTitle,
-			notifyBody:      r.NotifyBody,
-			urgency:         r.Urgency,
+			Name:                  r.Name,
+			cmdlineContains:       r.Match.CmdlineContains,
+			username:              r.Match.Username,
+			exitCode:              r.Match.ExitCode,
+			becameRoot:            r.Match.BecameRoot,
+			resource:              r.Match.Resource,
+			parentCmdlineContains: r.Match.ParentCmdlineContains,
+			depthFrom:             r.Match.DepthFrom,
+			actionNames:           r.Actions,
+			notifyTitle:           r.NotifyTitle,
+			notifyBody:            r.NotifyBody,
+			notifyBodyCoalesced:   r.NotifyBodyCoalesced,
+			urgency:               r.Urgency,
+		}
+		if len(r.Match.On) > 0 {
+			c.on = make(map[string]bool, len(r.Match.On))
+			for _, kind := range r.Match.On {
+				c.on[kind] = true
+			}
 		}
 		if c.urgency == "" {
 			c.urgency = "normal"
@@ -85,6 +233,22 @@ func buildCriteria(raw []criterionRaw) ([]*Criterion, error) {
 		if c.notifyBody == "" {
 			c.notifyBody = "PID {pid}: {name}"
 		}
+		if c.notifyBodyCoalesced == "" {
+			c.notifyBodyCoalesced = "{count} matching processes: {pids}"
+		}
+		if c.resource != nil && c.resource.SustainedFor <= 0 {
+			c.resource.SustainedFor = 1
+		}
+		if len(c.actionNames) == 0 {
+			c.actionNames = []string{"dbus"}
+		}
+		if r.Throttle != nil {
+			th, err := buildThrottle(r.Throttle)
+			if err != nil {
+				return nil, fmt.Errorf("criterion %q: %w", r.Name, err)
+			}
+			c.throttle = th
+		}
 		if r.Match.NameRegex != "" {
 			re, err := regexp.Compile("(?i)" + r.Match.NameRegex)
 			if err != nil {
@@ -92,11 +256,46 @@ func buildCriteria(raw []criterionRaw) ([]*Criterion, error) {
 			}
 			c.nameRegex = re
 		}
+		if r.Match.ParentNameRegex != "" {
+			re, err := regexp.Compile("(?i)" + r.Match.ParentNameRegex)
+			if err != nil {
+				return nil, fmt.Errorf("criterion %q: invalid parent_name_regex: %w", r.Name, err)
+			}
+			c.parentNameRegex = re
+		}
+		if r.Match.AncestorNameRegex != "" {
+			re, err := regexp.Compile("(?i)" + r.Match.AncestorNameRegex)
+			if err != nil {
+				return nil, fmt.Errorf("criterion %q: invalid ancestor_name_regex: %w", r.Name, err)
+			}
+			c.ancestorNameRegex = re
+		}
+		if r.Match.Signal != "" {
+			n, ok := signalNumber(r.Match.Signal)
+			if !ok {
+				return nil, fmt.Errorf("criterion %q: unknown signal %q", r.Name, r.Match.Signal)
+			}
+			c.signal = n
+		}
 		out = append(out, c)
 	}
 	return out, nil
 }
 
+// warnUnsupportedEvents logs one warning per criterion/event-kind pair that
+// caps can't actually deliver (e.g. "coredump" under the kqueue or polling
+// source), so a criterion that will silently never fire doesn't fail
+// quietly. sourceName is only used for the log line.
+func warnUnsupportedEvents(sourceName string, caps SourceCaps, criteria []*Criterion) {
+	for _, c := range criteria {
+		for _, kind := range c.eventKinds() {
+			if !caps.supports(kind) {
+				log.Printf("Criterion %q: event kind %q is not supported by the %s source and will never match", c.Name, kind, sourceName)
+			}
+		}
+	}
+}
+
 // tmplVar matches Python-style {key} placeholders.
 var tmplVar = regexp.MustCompile(`\{(\w+)\}`)
 
@@ -109,15 +308,11 @@ func formatTemplate(tmpl string, ctx map[string]string) string {
 	})
 }
 
-func (c *Criterion) matches(proc *process.Process) bool {
-	name, err := proc.Name()
-	if err != nil {
-		return false
-	}
-	parts, _ := proc.CmdlineSlice()
-	cmdline := strings.Join(parts, " ")
-	username, _ := proc.Username()
-
+// matchesIdentity checks the name/cmdline/username fields against a resolved
+// identity, independent of any particular event. It's shared by matches (for
+// event-triggered criteria) and the resource sampler (for continuous ones,
+// which have no single triggering event to hang a process lookup off of).
+func (c *Criterion) matchesIdentity(name, cmdline, username string) bool {
 	if c.nameRegex != nil && !c.nameRegex.MatchString(name) {
 		return false
 	}
@@ -132,59 +327,408 @@ func (c *Criterion) matches(proc *process.Process) bool {
 	return true
 }
 
-func (c *Criterion) formatNotification(proc *process.Process) (string, string) {
-	name, _ := proc.Name()
+// procIdentity resolves the name/cmdline/username triple off a live process.
+// ok is false if proc is nil or the process has already gone away.
+func procIdentity(proc *process.Process) (name, cmdline, username string, ok bool) {
+	if proc == nil {
+		return "", "", "", false
+	}
+	name, err := proc.Name()
+	if err != nil {
+		return "", "", "", false
+	}
 	parts, _ := proc.CmdlineSlice()
-	username, _ := proc.Username()
+	username, _ = proc.Username()
+	return name, strings.Join(parts, " "), username, true
+}
+
+// resolveIdentity returns the best available name/cmdline/username for ev:
+// the live process when there is one, falling back to the lineage tree's
+// last-known values (captured at fork/exec) for an exit event, whose process
+// has already exited by the time we observe it.
+func resolveIdentity(ev ProcEvent, proc *process.Process, tree *processTree) (name, cmdline, username string, ok bool) {
+	if name, cmdline, username, ok = procIdentity(proc); ok {
+		return name, cmdline, username, true
+	}
+	if tree == nil {
+		return "", "", "", false
+	}
+	return tree.identity(ev.Pid)
+}
+
+// resolveParentPid returns the best available parent pid for ev: the
+// event's own ParentPid when it has one, falling back to the tree's
+// last-known Ppid for ev.Pid (set at an earlier fork) and then a live
+// lookup. This matters for exec events in particular — CN_PROC exec
+// payloads carry no parent pid at all, so ev.ParentPid is always 0 there.
+func resolveParentPid(ev ProcEvent, proc *process.Process, tree *processTree) int32 {
+	if ev.ParentPid != 0 {
+		return ev.ParentPid
+	}
+	if tree != nil {
+		if ppid, ok := tree.ppid(ev.Pid); ok {
+			return ppid
+		}
+	}
+	if proc != nil {
+		if ppid, err := proc.Ppid(); err == nil {
+			return ppid
+		}
+	}
+	return 0
+}
+
+// isResourceOnly reports whether c has a resource block and nothing else
+// that could ever fail an event-triggered match — no "on" list, no identity
+// fields, no parent/ancestor/exit/signal constraints. Such a criterion has
+// no business matching process-lifecycle events at all: it should only ever
+// fire through resourceSampler's sustained-threshold check, not as a side
+// effect of wantsEvent's "no on list means exec-only" default.
+func (c *Criterion) isResourceOnly() bool {
+	return c.resource != nil &&
+		len(c.on) == 0 &&
+		c.nameRegex == nil &&
+		len(c.cmdlineContains) == 0 &&
+		c.username == "" &&
+		c.parentNameRegex == nil &&
+		c.ancestorNameRegex == nil &&
+		len(c.parentCmdlineContains) == 0 &&
+		!c.becameRoot &&
+		c.exitCode == nil &&
+		c.signal == 0
+}
+
+// matches reports whether ev satisfies c. proc is the live process for
+// ev.Pid when available; it is nil for events whose process has already
+// gone away (most commonly an exit event), in which case the name/cmdline/
+// username fields are checked against the lineage tree's last-known values
+// for ev.Pid and fail if the tree never tracked it. tree is the watcher's
+// process lineage, also consulted for parent/ancestor matching.
+func (c *Criterion) matches(ev ProcEvent, proc *process.Process, tree *processTree) bool {
+	if c.isResourceOnly() {
+		return false
+	}
+	if !c.wantsEvent(ev.Kind) {
+		return false
+	}
+
+	if c.exitCode != nil && (ev.Kind != EventExit || int(ev.ExitCode) != *c.exitCode) {
+		return false
+	}
+	if c.signal != 0 && (ev.Kind != EventExit || int(ev.ExitSignal) != c.signal) {
+		return false
+	}
+	if c.becameRoot && (ev.Kind != EventSetuid || ev.EUID != 0) {
+		return false
+	}
+
+	if c.nameRegex != nil || len(c.cmdlineContains) > 0 || c.username != "" {
+		name, cmdline, username, ok := resolveIdentity(ev, proc, tree)
+		if !ok || !c.matchesIdentity(name, cmdline, username) {
+			return false
+		}
+	}
+
+	if c.parentNameRegex != nil || len(c.parentCmdlineContains) > 0 || c.ancestorNameRegex != nil {
+		ppid := resolveParentPid(ev, proc, tree)
+
+		if c.parentNameRegex != nil {
+			parent, err := process.NewProcess(ppid)
+			if err != nil {
+				return false
+			}
+			parentName, err := parent.Name()
+			if err != nil || !c.parentNameRegex.MatchString(parentName) {
+				return false
+			}
+		}
+
+		if len(c.parentCmdlineContains) > 0 && !parentCmdlineContains(ppid, c.parentCmdlineContains, tree) {
+			return false
+		}
+
+		if c.ancestorNameRegex != nil {
+			if tree == nil || !tree.hasAncestorMatching(ppid, c.ancestorNameRegex, c.depthFrom) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (c *Criterion) formatNotification(ev ProcEvent, proc *process.Process, tree *processTree) (string, string) {
+	name, cmdline, username, _ := resolveIdentity(ev, proc, tree)
+	ppid := resolveParentPid(ev, proc, tree)
+
+	var parentName, parentCmdline string
+	if parent, err := process.NewProcess(ppid); err == nil {
+		n, _ := parent.Name()
+		parts, _ := parent.CmdlineSlice()
+		parentName, parentCmdline = n, strings.Join(parts, " ")
+	}
+	var ancestry string
+	if tree != nil {
+		ancestry = tree.ancestryString(ppid, name)
+	}
+
 	ctx := map[string]string{
-		"name":     name,
-		"pid":      fmt.Sprintf("%d", proc.Pid),
-		"cmdline":  strings.Join(parts, " "),
-		"username": username,
+		"event":          ev.Kind,
+		"name":           name,
+		"pid":            fmt.Sprintf("%d", ev.Pid),
+		"ppid":           fmt.Sprintf("%d", ppid),
+		"cmdline":        cmdline,
+		"username":       username,
+		"exit_code":      fmt.Sprintf("%d", ev.ExitCode),
+		"signal":         fmt.Sprintf("%d", ev.ExitSignal),
+		"parent_name":    parentName,
+		"parent_cmdline": parentCmdline,
+		"ancestry":       ancestry,
 	}
 	return formatTemplate(c.notifyTitle, ctx), formatTemplate(c.notifyBody, ctx)
 }
 
-// ---------------------------------------------------------------------------
-// Notifications (D-Bus)
-// ---------------------------------------------------------------------------
-
-func sendNotification(title, body, urgency string) error {
-	conn, err := dbus.SessionBus()
-	if err != nil {
-		return fmt.Errorf("D-Bus session: %w", err)
+// formatResourceNotification renders notifyTitle/notifyBody for a resource
+// criterion firing, adding {cpu_percent}, {rss_mb}, {cpu_time}, {ppid}, and
+// {ancestry} to the usual {name}/{pid}/{cmdline}/{username} placeholders.
+func (c *Criterion) formatResourceNotification(proc *process.Process, sample resourceSample, tree *processTree) (string, string) {
+	name, _ := proc.Name()
+	parts, _ := proc.CmdlineSlice()
+	username, _ := proc.Username()
+	ppid, _ := proc.Ppid()
+	var ancestry string
+	if tree != nil {
+		ancestry = tree.ancestryString(ppid, name)
 	}
-	urgencyMap := map[string]byte{"low": 0, "normal": 1, "critical": 2}
-	u, ok := urgencyMap[urgency]
-	if !ok {
-		u = 1
+	ctx := map[string]string{
+		"event":       "resource",
+		"name":        name,
+		"pid":         fmt.Sprintf("%d", proc.Pid),
+		"ppid":        fmt.Sprintf("%d", ppid),
+		"cmdline":     strings.Join(parts, " "),
+		"username":    username,
+		"cpu_percent": fmt.Sprintf("%.1f", sample.CPUPercent),
+		"rss_mb":      fmt.Sprintf("%.1f", sample.RSSMB),
+		"cpu_time":    fmt.Sprintf("%.1f", sample.CPUTimeSec),
+		"ancestry":    ancestry,
 	}
-	hints := map[string]dbus.Variant{"urgency": dbus.MakeVariant(u)}
-	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
-	return obj.Call(
-		"org.freedesktop.Notifications.Notify", 0,
-		"process_watcher", uint32(0), "", title, body, []string{}, hints, int32(-1),
-	).Err
+	return formatTemplate(c.notifyTitle, ctx), formatTemplate(c.notifyBody, ctx)
 }
 
 // ---------------------------------------------------------------------------
 // ProcessWatcher
 // ---------------------------------------------------------------------------
 
+// matchHistoryLimit bounds the in-memory ring buffer consulted by the gRPC
+// RecentMatches RPC.
+const matchHistoryLimit = 500
+
+// Match records one criterion firing on one event, kept around for the gRPC
+// control plane's RecentMatches/Subscribe RPCs.
+type Match struct {
+	Criterion string
+	Event     ProcEvent
+	Title     string
+	Body      string
+}
+
 type ProcessWatcher struct {
 	configPath string
+	sourcePref string
+	grpcListen string
+	grpcTLS    grpcTLSConfig
 	mu         sync.RWMutex
 	criteria   []*Criterion
+
+	matchMu sync.Mutex
+	matches []Match
+
+	subMu       sync.Mutex
+	subscribers map[chan Match]struct{}
+
+	resSampler resourceSampler
+
+	actionsMu sync.RWMutex
+	actions   map[string]Action
+	actionSem chan struct{}
+
+	tree *processTree
+
+	throttler *throttler
+
+	// sourceName/sourceCaps are set once run() picks the backend, and used
+	// by warnUnsupportedEvents to flag criteria asking for event kinds
+	// that backend can never deliver. Zero value before run() is called,
+	// so reloadConfig and addCriterion skip the check until then.
+	sourceName string
+	sourceCaps SourceCaps
 }
 
-func newProcessWatcher(configPath string, criteria []*Criterion) *ProcessWatcher {
+func newProcessWatcher(configPath string, criteria []*Criterion, actions map[string]Action, sourcePref, grpcListen string, grpcTLS grpcTLSConfig, lineageMaxPids int) *ProcessWatcher {
 	abs, err := filepath.Abs(configPath)
 	if err != nil {
 		abs = configPath
 	}
-	return &ProcessWatcher{
-		configPath: abs,
-		criteria:   criteria,
+	w := &ProcessWatcher{
+		configPath:  abs,
+		sourcePref:  sourcePref,
+		grpcListen:  grpcListen,
+		grpcTLS:     grpcTLS,
+		criteria:    criteria,
+		subscribers: make(map[chan Match]struct{}),
+		actions:     actions,
+		actionSem:   make(chan struct{}, actionWorkerPoolSize),
+		tree:        newProcessTree(lineageMaxPids),
+	}
+	w.resSampler = newResourceSampler(w, resourceSampleInterval)
+	w.throttler = newThrottler(w)
+	return w
+}
+
+// dispatchActions delivers msg through every action name c references,
+// concurrently and bounded by actionSem so a slow webhook or exec hook can't
+// stall event processing. An unknown action name is logged and skipped.
+func (w *ProcessWatcher) dispatchActions(c *Criterion, ev ProcEvent, msg RenderedMessage) {
+	w.actionsMu.RLock()
+	actions := w.actions
+	w.actionsMu.RUnlock()
+
+	for _, name := range c.actionNames {
+		act, ok := actions[name]
+		if !ok {
+			log.Printf("Criterion %q: unknown action %q", c.Name, name)
+			continue
+		}
+		go func(name string, act Action) {
+			w.actionSem <- struct{}{}
+			defer func() { <-w.actionSem }()
+			timeout := defaultActionTimeout
+			if ea, ok := act.(*execAction); ok && ea.timeout > timeout {
+				timeout = ea.timeout
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := act.Deliver(ctx, ev, msg); err != nil {
+				log.Printf("Action %q delivery error: %v", name, err)
+			}
+		}(name, act)
+	}
+}
+
+// listCriteria returns a snapshot of the currently loaded criteria.
+func (w *ProcessWatcher) listCriteria() []*Criterion {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	out := make([]*Criterion, len(w.criteria))
+	copy(out, w.criteria)
+	return out
+}
+
+// addCriterion appends a criterion to the running config. It does not
+// persist to configPath, so a ReloadConfig or restart will drop it.
+func (w *ProcessWatcher) addCriterion(raw criterionRaw) error {
+	built, err := buildCriteria([]criterionRaw{raw})
+	if err != nil {
+		return err
+	}
+	if len(w.sourceCaps.Events) > 0 {
+		warnUnsupportedEvents(w.sourceName, w.sourceCaps, built)
+	}
+	w.mu.Lock()
+	w.criteria = append(w.criteria, built...)
+	w.mu.Unlock()
+	return nil
+}
+
+// removeCriterion drops the named criterion from the running config,
+// reporting whether anything was removed.
+func (w *ProcessWatcher) removeCriterion(name string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, c := range w.criteria {
+		if c.Name == name {
+			w.criteria = append(w.criteria[:i], w.criteria[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// recordMatch appends to the ring buffer and fans the match out to every
+// gRPC Subscribe caller; a full subscriber channel drops the match rather
+// than blocking event processing.
+func (w *ProcessWatcher) recordMatch(m Match) {
+	w.matchMu.Lock()
+	w.matches = append(w.matches, m)
+	if len(w.matches) > matchHistoryLimit {
+		w.matches = w.matches[len(w.matches)-matchHistoryLimit:]
+	}
+	w.matchMu.Unlock()
+
+	w.subMu.Lock()
+	defer w.subMu.Unlock()
+	for ch := range w.subscribers {
+		select {
+		case ch <- m:
+		default:
+			log.Printf("gRPC: subscriber channel full, dropping match for criterion %q", m.Criterion)
+		}
+	}
+}
+
+// recentMatches returns up to limit of the most recent matches, newest
+// first. limit <= 0 returns the full history.
+func (w *ProcessWatcher) recentMatches(limit int) []Match {
+	w.matchMu.Lock()
+	defer w.matchMu.Unlock()
+	n := len(w.matches)
+	if limit > 0 && limit < n {
+		n = limit
+	}
+	out := make([]Match, n)
+	for i := 0; i < n; i++ {
+		out[i] = w.matches[len(w.matches)-1-i]
+	}
+	return out
+}
+
+// subscribeMatches registers a new fan-out channel for live matches. The
+// returned cancel func must be called to unregister and close it.
+func (w *ProcessWatcher) subscribeMatches() (<-chan Match, func()) {
+	ch := make(chan Match, 32)
+	w.subMu.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.subMu.Unlock()
+
+	cancel := func() {
+		w.subMu.Lock()
+		if _, ok := w.subscribers[ch]; ok {
+			delete(w.subscribers, ch)
+			close(ch)
+		}
+		w.subMu.Unlock()
+	}
+	return ch, cancel
+}
+
+// selectSource resolves the -source flag to a concrete ProcessSource,
+// falling back to polling when the requested (or, in "auto" mode, the only
+// available) native backend doesn't exist on this platform.
+func (w *ProcessWatcher) selectSource() ProcessSource {
+	switch w.sourcePref {
+	case "poll":
+		return newPollingSource(fallbackPollInterval)
+	case "netlink", "kqueue":
+		if s := nativeSource(); s != nil && s.Name() == w.sourcePref {
+			return s
+		}
+		log.Printf("Requested source %q is unavailable on this platform, falling back to polling", w.sourcePref)
+		return newPollingSource(fallbackPollInterval)
+	default: // "auto" or unset
+		if s := nativeSource(); s != nil {
+			return s
+		}
+		return newPollingSource(fallbackPollInterval)
 	}
 }
 
@@ -194,52 +738,69 @@ func (w *ProcessWatcher) reloadConfig() {
 		log.Printf("Config reload failed: %v", err)
 		return
 	}
-	var raw []criterionRaw
-	if err := json.Unmarshal(data, &raw); err != nil {
+	rawCriteria, rawActions, err := parseConfigFile(data)
+	if err != nil {
 		log.Printf("Config reload failed, keeping existing criteria: %v", err)
 		return
 	}
-	criteria, err := buildCriteria(raw)
+	criteria, err := buildCriteria(rawCriteria)
 	if err != nil {
 		log.Printf("Config reload failed, keeping existing criteria: %v", err)
 		return
 	}
+	actions, err := buildActions(rawActions)
+	if err != nil {
+		log.Printf("Config reload failed, keeping existing criteria: %v", err)
+		return
+	}
+	if len(w.sourceCaps.Events) > 0 {
+		warnUnsupportedEvents(w.sourceName, w.sourceCaps, criteria)
+	}
 	w.mu.Lock()
 	w.criteria = criteria
 	w.mu.Unlock()
-	log.Printf("Config reloaded: %d criteria from %s", len(criteria), w.configPath)
+	w.actionsMu.Lock()
+	w.actions = actions
+	w.actionsMu.Unlock()
+	log.Printf("Config reloaded: %d criteria, %d actions from %s", len(criteria), len(actions), w.configPath)
 }
 
-func (w *ProcessWatcher) snapshot() map[int32]struct{} {
-	pids, _ := process.Pids()
-	m := make(map[int32]struct{}, len(pids))
-	for _, pid := range pids {
-		m[pid] = struct{}{}
-	}
-	return m
-}
-
-func (w *ProcessWatcher) checkNew(newPIDs map[int32]struct{}) {
+func (w *ProcessWatcher) handleEvent(ev ProcEvent) {
 	w.mu.RLock()
 	criteria := make([]*Criterion, len(w.criteria))
 	copy(criteria, w.criteria)
 	w.mu.RUnlock()
 
-	for pid := range newPIDs {
-		proc, err := process.NewProcess(pid)
-		if err != nil {
-			continue // process already gone
-		}
-		for _, c := range criteria {
-			if c.matches(proc) {
-				title, body := c.formatNotification(proc)
-				log.Printf("MATCH [%s] — %s | %s", c.Name, title, body)
-				if err := sendNotification(title, body, c.urgency); err != nil {
-					log.Printf("Notification error: %v", err)
-				}
+	// Exit events almost always race the process's own teardown, so a
+	// lookup failure here is expected rather than exceptional.
+	proc, err := process.NewProcess(ev.Pid)
+	if err != nil {
+		proc = nil
+	}
+
+	if ev.Kind != EventExit {
+		w.tree.observe(ev, proc)
+	}
+
+	for _, c := range criteria {
+		if c.matches(ev, proc, w.tree) {
+			title, body := c.formatNotification(ev, proc, w.tree)
+			log.Printf("MATCH [%s] (%s) — %s | %s", c.Name, ev.Kind, title, body)
+			w.throttler.admit(c, ev, title, body)
+			w.recordMatch(Match{Criterion: c.Name, Event: ev, Title: title, Body: body})
+			w.tree.recordMatch(ev.Pid, c.Name)
+		}
+		if c.resource != nil {
+			if name, cmdline, username, ok := procIdentity(proc); ok && c.matchesIdentity(name, cmdline, username) {
+				w.resSampler.track(ev.Pid, c)
 			}
 		}
 	}
+
+	if ev.Kind == EventExit {
+		w.tree.observe(ev, proc)
+		w.resSampler.untrack(ev.Pid)
+	}
 }
 
 func (w *ProcessWatcher) run() {
@@ -287,52 +848,53 @@ func (w *ProcessWatcher) run() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	defer w.throttler.shutdown()
 
-	pidCh, err := listenProcExec(ctx)
-	if err != nil {
-		log.Printf("Warning: netlink CN_PROC unavailable (%v), falling back to polling every %v", err, fallbackPollInterval)
-		w.runPolling(sigCh)
-		return
-	}
-	log.Printf("Process watcher started in netlink CN_PROC mode.")
-
-	for {
-		select {
-		case pid, ok := <-pidCh:
-			if !ok {
-				log.Printf("Netlink channel closed, falling back to polling every %v", fallbackPollInterval)
-				w.runPolling(sigCh)
-				return
+	if w.grpcListen != "" {
+		go func() {
+			if err := startGRPCServer(ctx, w, w.grpcListen, w.grpcTLS); err != nil {
+				log.Printf("gRPC control plane stopped: %v", err)
 			}
-			w.checkNew(map[int32]struct{}{pid: {}})
-		case s := <-sigCh:
-			log.Printf("Received signal %v, stopping.", s)
-			return
-		}
+		}()
 	}
-}
 
-func (w *ProcessWatcher) runPolling(sigCh chan os.Signal) {
-	log.Printf("Process watcher polling every %v.", fallbackPollInterval)
-	seen := w.snapshot()
+	go w.resSampler.run(ctx)
 
-	ticker := time.NewTicker(fallbackPollInterval)
-	defer ticker.Stop()
+	w.tree.seed()
+	log.Printf("Process lineage seeded with %d running processes", w.tree.size())
+
+	source := w.selectSource()
+	log.Printf("Process watcher starting with %q backend.", source.Name())
+	eventCh, err := source.Subscribe(ctx)
+	if err != nil {
+		log.Printf("Warning: %s backend unavailable (%v), falling back to polling every %v", source.Name(), err, fallbackPollInterval)
+		source = newPollingSource(fallbackPollInterval)
+		eventCh, err = source.Subscribe(ctx)
+		if err != nil {
+			log.Fatalf("polling fallback failed: %v", err)
+		}
+	}
+	w.sourceName = source.Name()
+	w.sourceCaps = source.Capabilities()
+	warnUnsupportedEvents(w.sourceName, w.sourceCaps, w.listCriteria())
 
 	for {
 		select {
-		case <-ticker.C:
-			current := w.snapshot()
-			newPIDs := make(map[int32]struct{})
-			for pid := range current {
-				if _, ok := seen[pid]; !ok {
-					newPIDs[pid] = struct{}{}
+		case ev, ok := <-eventCh:
+			if !ok {
+				if source.Name() == "poll" {
+					log.Printf("Polling source stopped, shutting down.")
+					return
 				}
+				log.Printf("%s backend channel closed, falling back to polling every %v", source.Name(), fallbackPollInterval)
+				source = newPollingSource(fallbackPollInterval)
+				if eventCh, err = source.Subscribe(ctx); err != nil {
+					log.Printf("polling fallback failed: %v", err)
+					return
+				}
+				continue
 			}
-			if len(newPIDs) > 0 {
-				w.checkNew(newPIDs)
-			}
-			seen = current
+			w.handleEvent(ev)
 		case s := <-sigCh:
 			log.Printf("Received signal %v, stopping.", s)
 			return
@@ -346,10 +908,20 @@ func (w *ProcessWatcher) runPolling(sigCh chan os.Signal) {
 
 func main() {
 	configFile := flag.String("config", "", "Path to JSON config file (default: ./config.json)")
+	sourceFlag := flag.String("source", "auto", "Process event source: auto|netlink|kqueue|poll")
+	grpcListen := flag.String("grpc-listen", "", "Control-plane listen address: a unix socket path, or tcp://host:port (default: $XDG_RUNTIME_DIR/pnotify.sock)")
+	grpcTLSCert := flag.String("grpc-tls-cert", "", "TLS certificate for -grpc-listen tcp://... (enables mTLS with -grpc-tls-ca)")
+	grpcTLSKey := flag.String("grpc-tls-key", "", "TLS private key for -grpc-listen tcp://...")
+	grpcTLSCA := flag.String("grpc-tls-ca", "", "CA bundle used to verify client certs on -grpc-listen tcp://...")
+	lineageMaxPids := flag.Int("lineage-max-pids", processTreeSizeLimit, "Cap on tracked pids in the process lineage tree")
 	flag.Parse()
 
 	log.SetFlags(log.Ltime)
 
+	if !validSourceNames[*sourceFlag] {
+		log.Fatalf("Invalid -source %q (want auto, netlink, kqueue, or poll)", *sourceFlag)
+	}
+
 	configPath := *configFile
 	if configPath == "" {
 		configPath = "config.json"
@@ -359,15 +931,25 @@ func main() {
 	if err != nil {
 		log.Fatalf("Config file not found: %s", configPath)
 	}
-	var raw []criterionRaw
-	if err := json.Unmarshal(data, &raw); err != nil {
+	rawCriteria, rawActions, err := parseConfigFile(data)
+	if err != nil {
+		log.Fatalf("Invalid config: %v", err)
+	}
+	criteria, err := buildCriteria(rawCriteria)
+	if err != nil {
 		log.Fatalf("Invalid config: %v", err)
 	}
-	criteria, err := buildCriteria(raw)
+	actions, err := buildActions(rawActions)
 	if err != nil {
 		log.Fatalf("Invalid config: %v", err)
 	}
 
-	watcher := newProcessWatcher(configPath, criteria)
+	listen := *grpcListen
+	if listen == "" {
+		listen = defaultGRPCSocketPath()
+	}
+	tlsCfg := grpcTLSConfig{CertFile: *grpcTLSCert, KeyFile: *grpcTLSKey, CAFile: *grpcTLSCA}
+
+	watcher := newProcessWatcher(configPath, criteria, actions, *sourceFlag, listen, tlsCfg, *lineageMaxPids)
 	watcher.run()
 }
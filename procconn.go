@@ -9,15 +9,26 @@ import (
 	"fmt"
 	"log"
 	"syscall"
+	"time"
 )
 
 const (
 	CN_IDX_PROC          = 0x1
 	CN_VAL_PROC          = 0x1
-	PROC_EVENT_EXEC      = 0x00000002
 	PROC_CN_MCAST_LISTEN = 1
-	cnMsgHeaderSize      = 20 // fixed header before payload
-	procEventMinSize     = 24 // What+CPU+Timestamp+ExecPid+ExecTgid
+
+	// PROC_EVENT_* mirror the kernel's enum proc_cn_event (include/uapi/linux/cn_proc.h).
+	PROC_EVENT_FORK     = 0x00000001
+	PROC_EVENT_EXEC     = 0x00000002
+	PROC_EVENT_UID      = 0x00000004
+	PROC_EVENT_GID      = 0x00000040
+	PROC_EVENT_COMM     = 0x00000200
+	PROC_EVENT_COREDUMP = 0x40000000
+	PROC_EVENT_EXIT     = 0x80000000
+
+	cnMsgHeaderSize  = 20 // Idx+Val+Seq+Ack+Len+Flags
+	procHeaderSize   = 16 // What+CPU+Timestamp
+	procEventMinSize = cnMsgHeaderSize + procHeaderSize
 
 	netlinkConnector = 11 // NETLINK_CONNECTOR (not in syscall package)
 )
@@ -32,13 +43,48 @@ type cnMsg struct {
 	Flags uint16
 }
 
-// procEvent holds the fields relevant to PROC_EVENT_EXEC (24 bytes).
-type procEvent struct {
+// procEventHeader is the 16-byte header common to every proc connector event,
+// followed by a What-specific union payload.
+type procEventHeader struct {
 	What      uint32
 	CPU       uint32
 	Timestamp uint64
-	ExecPid   uint32
-	ExecTgid  uint32
+}
+
+// procEventFork is the PROC_EVENT_FORK payload (16 bytes).
+type procEventFork struct {
+	ParentPid  uint32
+	ParentTgid uint32
+	ChildPid   uint32
+	ChildTgid  uint32
+}
+
+// procEventExec is the PROC_EVENT_EXEC payload (8 bytes).
+type procEventExec struct {
+	ProcessPid  uint32
+	ProcessTgid uint32
+}
+
+// procEventExit is the PROC_EVENT_EXIT payload (24 bytes): the kernel's
+// exit_proc_event carries the exiting process's parent alongside pid/tgid and
+// exit status, so criteria matching on ancestry can still fire after the
+// process itself is gone.
+type procEventExit struct {
+	ProcessPid  uint32
+	ProcessTgid uint32
+	ExitCode    uint32
+	ExitSignal  uint32
+	ParentPid   uint32
+	ParentTgid  uint32
+}
+
+// procEventID is the PROC_EVENT_UID/PROC_EVENT_GID payload (16 bytes): pid,
+// tgid, and either {ruid,euid} or {rgid,egid} depending on What.
+type procEventID struct {
+	ProcessPid  uint32
+	ProcessTgid uint32
+	RID         uint32
+	EID         uint32
 }
 
 // procOp is the 4-byte payload for the subscribe/unsubscribe message.
@@ -76,37 +122,140 @@ func sendSubscribe(fd int, op uint32) error {
 	})
 }
 
-// parseCnProcExec extracts the PID from a PROC_EVENT_EXEC connector message.
-// Returns (pid, true) on a matching exec event, (0, false) otherwise.
-func parseCnProcExec(data []byte) (int32, bool) {
-	if len(data) < cnMsgHeaderSize+procEventMinSize {
-		return 0, false
+// parseCnProcEvent decodes one connector message into a ProcEvent. Returns
+// (event, true) for a recognized What, (ProcEvent{}, false) otherwise (unknown
+// connector, truncated payload, or an event kind we don't surface).
+func parseCnProcEvent(data []byte) (ProcEvent, bool) {
+	if len(data) < procEventMinSize {
+		return ProcEvent{}, false
 	}
 	r := bytes.NewReader(data)
 
 	var cn cnMsg
 	if err := binary.Read(r, binary.NativeEndian, &cn); err != nil {
-		return 0, false
+		return ProcEvent{}, false
 	}
 	if cn.Idx != CN_IDX_PROC || cn.Val != CN_VAL_PROC {
-		return 0, false
+		return ProcEvent{}, false
+	}
+
+	var hdr procEventHeader
+	if err := binary.Read(r, binary.NativeEndian, &hdr); err != nil {
+		return ProcEvent{}, false
 	}
 
-	var ev procEvent
-	if err := binary.Read(r, binary.NativeEndian, &ev); err != nil {
-		return 0, false
+	ev := ProcEvent{Timestamp: time.Now()}
+
+	switch hdr.What {
+	case PROC_EVENT_FORK:
+		var p procEventFork
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventFork
+		ev.Pid = int32(p.ChildPid)
+		ev.Tgid = int32(p.ChildTgid)
+		ev.ParentPid = int32(p.ParentPid)
+		ev.ParentTgid = int32(p.ParentTgid)
+	case PROC_EVENT_EXEC:
+		var p procEventExec
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventExec
+		ev.Pid = int32(p.ProcessPid)
+		ev.Tgid = int32(p.ProcessTgid)
+	case PROC_EVENT_EXIT:
+		var p procEventExit
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventExit
+		ev.Pid = int32(p.ProcessPid)
+		ev.Tgid = int32(p.ProcessTgid)
+		ev.ExitCode = int32(p.ExitCode)
+		ev.ExitSignal = int32(p.ExitSignal)
+		ev.ParentPid = int32(p.ParentPid)
+		ev.ParentTgid = int32(p.ParentTgid)
+	case PROC_EVENT_UID:
+		var p procEventID
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventSetuid
+		ev.Pid = int32(p.ProcessPid)
+		ev.Tgid = int32(p.ProcessTgid)
+		ev.RUID = p.RID
+		ev.EUID = p.EID
+	case PROC_EVENT_GID:
+		var p procEventID
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventSetgid
+		ev.Pid = int32(p.ProcessPid)
+		ev.Tgid = int32(p.ProcessTgid)
+		ev.RGID = p.RID
+		ev.EGID = p.EID
+	case PROC_EVENT_COMM:
+		// Fixed-size comm[16] payload; we only care that a rename happened,
+		// not the new name (the caller re-reads it from /proc).
+		if r.Len() < 16 {
+			return ProcEvent{}, false
+		}
+		var p procEventID // reuse: pid+tgid is the leading 8 bytes we need
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventComm
+		ev.Pid = int32(p.ProcessPid)
+		ev.Tgid = int32(p.ProcessTgid)
+	case PROC_EVENT_COREDUMP:
+		// Payload is {pid, tgid, parent_pid, parent_tgid}; procEventFork has the
+		// same four uint32 layout under different field names, so it's reused here.
+		var p procEventFork
+		if err := binary.Read(r, binary.NativeEndian, &p); err != nil {
+			return ProcEvent{}, false
+		}
+		ev.Kind = EventCoredump
+		ev.Pid = int32(p.ParentPid)
+		ev.Tgid = int32(p.ParentTgid)
+		ev.ParentPid = int32(p.ChildPid)
+		ev.ParentTgid = int32(p.ChildTgid)
+	default:
+		return ProcEvent{}, false
 	}
-	if ev.What != PROC_EVENT_EXEC {
-		return 0, false
+
+	return ev, true
+}
+
+// netlinkSource is the Linux ProcessSource backed by the CN_PROC connector.
+type netlinkSource struct{}
+
+func newNetlinkSource() *netlinkSource { return &netlinkSource{} }
+
+func (s *netlinkSource) Name() string { return "netlink" }
+
+func (s *netlinkSource) Capabilities() SourceCaps {
+	return SourceCaps{
+		Events: []string{EventExec, EventFork, EventExit, EventSetuid, EventSetgid, EventComm, EventCoredump},
+		Live:   true,
 	}
-	return int32(ev.ExecPid), true
 }
 
+func (s *netlinkSource) Subscribe(ctx context.Context) (<-chan ProcEvent, error) {
+	return listenProcExec(ctx)
+}
+
+// nativeSource returns this platform's kernel-backed ProcessSource.
+func nativeSource() ProcessSource { return newNetlinkSource() }
+
 // listenProcExec opens a netlink proc connector socket and returns a channel
-// that receives the PID of every exec()d process. Requires CAP_NET_ADMIN;
-// returns an error (typically EPERM) if the capability is absent, allowing
-// the caller to fall back to polling.
-func listenProcExec(ctx context.Context) (<-chan int32, error) {
+// that receives every fork/exec/exit/uid/gid/comm/coredump event the kernel
+// multiplexes onto CN_PROC. Requires CAP_NET_ADMIN; returns an error
+// (typically EPERM) if the capability is absent, allowing the caller to fall
+// back to polling.
+func listenProcExec(ctx context.Context) (<-chan ProcEvent, error) {
 	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, netlinkConnector)
 	if err != nil {
 		return nil, fmt.Errorf("netlink socket: %w", err)
@@ -125,7 +274,7 @@ func listenProcExec(ctx context.Context) (<-chan int32, error) {
 		return nil, fmt.Errorf("netlink subscribe: %w", err)
 	}
 
-	ch := make(chan int32, 64)
+	ch := make(chan ProcEvent, 256)
 	go func() {
 		defer syscall.Close(fd)
 		defer close(ch)
@@ -155,14 +304,14 @@ func listenProcExec(ctx context.Context) (<-chan int32, error) {
 				continue
 			}
 			for _, msg := range msgs {
-				pid, ok := parseCnProcExec(msg.Data)
+				ev, ok := parseCnProcEvent(msg.Data)
 				if !ok {
 					continue
 				}
 				select {
-				case ch <- pid:
+				case ch <- ev:
 				default:
-					log.Printf("netlink: pid channel full, dropping event for pid %d", pid)
+					log.Printf("netlink: event channel full, dropping %s event for pid %d", ev.Kind, ev.Pid)
 				}
 			}
 		}
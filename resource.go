@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// resourceSampleInterval is how often tracked pids are re-sampled for
+// cpu_percent_over/rss_mb_over/open_fds_over/io_read_mb_per_sec_over
+// thresholds.
+const resourceSampleInterval = 5 * time.Second
+
+// resourceSample is one reading of a tracked process's resource usage.
+type resourceSample struct {
+	CPUPercent     float64
+	RSSMB          float64
+	OpenFDs        int
+	IOReadMBPerSec float64
+	CPUTimeSec     float64
+}
+
+// resourceSampler periodically re-checks every pid a resource criterion is
+// tracking and fires a notification once a threshold has held for
+// SustainedFor consecutive samples. track/untrack are called from
+// ProcessWatcher.handleEvent as pids come and go; run drives the sampling
+// loop until ctx is canceled.
+type resourceSampler interface {
+	track(pid int32, c *Criterion)
+	untrack(pid int32)
+	run(ctx context.Context)
+}
+
+// overThreshold reports whether sample exceeds every bound rm configures;
+// an unconfigured bound doesn't block the match, mirroring how the other
+// criterionMatch fields combine with AND semantics.
+func overThreshold(rm *resourceMatch, sample resourceSample) bool {
+	if rm.CPUPercentOver != nil && sample.CPUPercent <= *rm.CPUPercentOver {
+		return false
+	}
+	if rm.RSSMBOver != nil && sample.RSSMB <= *rm.RSSMBOver {
+		return false
+	}
+	if rm.OpenFDsOver != nil && sample.OpenFDs <= *rm.OpenFDsOver {
+		return false
+	}
+	if rm.IOReadMBPerSecOver != nil && sample.IOReadMBPerSec <= *rm.IOReadMBPerSecOver {
+		return false
+	}
+	return rm.CPUPercentOver != nil || rm.RSSMBOver != nil || rm.OpenFDsOver != nil || rm.IOReadMBPerSecOver != nil
+}
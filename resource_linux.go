@@ -0,0 +1,288 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+const cgroupRoot = "/sys/fs/cgroup"
+
+// trackedPid holds the sliding-window state for one criterion watching one
+// pid. cgroupPath and cgroupChecked are cached so a missing/non-v2 cgroup
+// isn't re-walked every sample.
+type trackedPid struct {
+	criterion *Criterion
+
+	cgroupPath    string
+	cgroupChecked bool
+
+	lastSampleAt time.Time
+	lastCPUUsec  uint64
+	lastIOBytes  uint64
+	haveLast     bool
+
+	consecutive int
+	firing      bool
+}
+
+type linuxResourceSampler struct {
+	w        *ProcessWatcher
+	interval time.Duration
+
+	mu      sync.Mutex
+	tracked map[int32]map[string]*trackedPid // pid -> criterion name -> state
+}
+
+func newResourceSampler(w *ProcessWatcher, interval time.Duration) resourceSampler {
+	return &linuxResourceSampler{w: w, interval: interval, tracked: make(map[int32]map[string]*trackedPid)}
+}
+
+func (s *linuxResourceSampler) track(pid int32, c *Criterion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	byName, ok := s.tracked[pid]
+	if !ok {
+		byName = make(map[string]*trackedPid)
+		s.tracked[pid] = byName
+	}
+	if _, ok := byName[c.Name]; !ok {
+		byName[c.Name] = &trackedPid{criterion: c}
+	}
+}
+
+func (s *linuxResourceSampler) untrack(pid int32) {
+	s.mu.Lock()
+	delete(s.tracked, pid)
+	s.mu.Unlock()
+}
+
+func (s *linuxResourceSampler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sampleAll()
+		}
+	}
+}
+
+func (s *linuxResourceSampler) sampleAll() {
+	s.mu.Lock()
+	pids := make([]int32, 0, len(s.tracked))
+	for pid := range s.tracked {
+		pids = append(pids, pid)
+	}
+	s.mu.Unlock()
+
+	for _, pid := range pids {
+		s.samplePid(pid)
+	}
+}
+
+func (s *linuxResourceSampler) samplePid(pid int32) {
+	proc, err := process.NewProcess(pid)
+	if err != nil {
+		// The exit event should already have untracked this pid; this is a
+		// defensive catch-all for a poll-mode race where exit wasn't seen.
+		s.untrack(pid)
+		return
+	}
+
+	s.mu.Lock()
+	byName := s.tracked[pid]
+	states := make([]*trackedPid, 0, len(byName))
+	for _, t := range byName {
+		states = append(states, t)
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range states {
+		if !t.cgroupChecked {
+			t.cgroupPath, _ = cgroupPathForPid(pid)
+			t.cgroupChecked = true
+		}
+
+		sample, ok := s.readSample(pid, t, now)
+		if !ok {
+			continue
+		}
+
+		if overThreshold(t.criterion.resource, sample) {
+			t.consecutive++
+		} else {
+			t.consecutive = 0
+			t.firing = false
+		}
+
+		if t.consecutive >= t.criterion.resource.SustainedFor && !t.firing {
+			t.firing = true
+			title, body := t.criterion.formatResourceNotification(proc, sample, s.w.tree)
+			log.Printf("MATCH [%s] (resource) — %s | %s", t.criterion.Name, title, body)
+			ev := ProcEvent{Kind: "resource", Pid: pid, Timestamp: now}
+			s.w.throttler.admit(t.criterion, ev, title, body)
+			s.w.recordMatch(Match{
+				Criterion: t.criterion.Name,
+				Event:     ev,
+				Title:     title,
+				Body:      body,
+			})
+		}
+	}
+}
+
+// readSample gathers one resourceSample for pid, preferring cgroup v2
+// accounting files and falling back to /proc/<pid> when no cgroup v2 path
+// was found (e.g. a cgroup v1 host, or a pid not in its own cgroup).
+func (s *linuxResourceSampler) readSample(pid int32, t *trackedPid, now time.Time) (resourceSample, bool) {
+	var sample resourceSample
+	elapsed := now.Sub(t.lastSampleAt).Seconds()
+	if !t.haveLast || elapsed <= 0 {
+		elapsed = s.interval.Seconds()
+	}
+
+	cpuUsec, rssBytes, ioBytes, fds, ok := readCgroupMetrics(t.cgroupPath, pid)
+	if !ok {
+		cpuUsec, rssBytes, ioBytes, fds, ok = readProcfsMetrics(pid)
+	}
+	if !ok {
+		return sample, false
+	}
+
+	sample.RSSMB = float64(rssBytes) / (1024 * 1024)
+	sample.OpenFDs = fds
+	sample.CPUTimeSec = float64(cpuUsec) / 1e6
+
+	if t.haveLast {
+		if cpuUsec >= t.lastCPUUsec {
+			sample.CPUPercent = (float64(cpuUsec-t.lastCPUUsec) / 1e6) / elapsed * 100
+		}
+		if ioBytes >= t.lastIOBytes {
+			sample.IOReadMBPerSec = float64(ioBytes-t.lastIOBytes) / (1024 * 1024) / elapsed
+		}
+	}
+
+	t.lastCPUUsec = cpuUsec
+	t.lastIOBytes = ioBytes
+	t.lastSampleAt = now
+	t.haveLast = true
+	return sample, true
+}
+
+// cgroupPathForPid resolves a pid's unified (v2) cgroup, returning ("",
+// false) on a v1-only host or a read failure.
+func cgroupPathForPid(pid int32) (string, bool) {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rel, ok := strings.CutPrefix(line, "0::"); ok {
+			return cgroupRoot + rel, true
+		}
+	}
+	return "", false
+}
+
+// readCgroupMetrics reads cpu.stat/memory.current/io.stat from a cached
+// cgroup v2 path. It returns ok=false if path is empty (not yet resolved,
+// or not cgroup v2), letting the caller fall back to /proc/<pid>.
+func readCgroupMetrics(path string, pid int32) (cpuUsec, rssBytes, ioBytes uint64, fds int, ok bool) {
+	if path == "" {
+		return 0, 0, 0, 0, false
+	}
+
+	cpuStat, err := os.ReadFile(path + "/cpu.stat")
+	if err != nil {
+		return 0, 0, 0, 0, false
+	}
+	for _, line := range strings.Split(string(cpuStat), "\n") {
+		if rest, ok := strings.CutPrefix(line, "usage_usec "); ok {
+			cpuUsec, _ = strconv.ParseUint(strings.TrimSpace(rest), 10, 64)
+		}
+	}
+
+	if mem, err := os.ReadFile(path + "/memory.current"); err == nil {
+		rssBytes, _ = strconv.ParseUint(strings.TrimSpace(string(mem)), 10, 64)
+	}
+
+	if io, err := os.ReadFile(path + "/io.stat"); err == nil {
+		for _, line := range strings.Split(string(io), "\n") {
+			for _, field := range strings.Fields(line) {
+				if rest, ok := strings.CutPrefix(field, "rbytes="); ok {
+					if n, err := strconv.ParseUint(rest, 10, 64); err == nil {
+						ioBytes += n
+					}
+				}
+			}
+		}
+	}
+
+	fds = countOpenFDs(pid)
+	return cpuUsec, rssBytes, ioBytes, fds, true
+}
+
+// readProcfsMetrics is the fallback used when a pid has no cgroup v2 path:
+// cpu ticks and RSS come from /proc/<pid>/stat and /status, read bytes from
+// /proc/<pid>/io.
+func readProcfsMetrics(pid int32) (cpuUsec, rssBytes, ioBytes uint64, fds int, ok bool) {
+	statBytes, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return 0, 0, 0, 0, false
+	}
+	// Fields after the ")" that closes comm are space-separated and
+	// positionally fixed; utime/stime are fields 14/15 (1-indexed overall).
+	stat := string(statBytes)
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+	if len(fields) < 15 {
+		return 0, 0, 0, 0, false
+	}
+	utime, _ := strconv.ParseUint(fields[11], 10, 64)
+	stime, _ := strconv.ParseUint(fields[12], 10, 64)
+	const clockTicksPerSec = 100 // USER_HZ; correct on the overwhelming majority of Linux builds
+	cpuUsec = (utime + stime) * (1000000 / clockTicksPerSec)
+
+	if status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid)); err == nil {
+		for _, line := range strings.Split(string(status), "\n") {
+			if rest, ok := strings.CutPrefix(line, "VmRSS:"); ok {
+				fields := strings.Fields(rest)
+				if len(fields) > 0 {
+					kb, _ := strconv.ParseUint(fields[0], 10, 64)
+					rssBytes = kb * 1024
+				}
+			}
+		}
+	}
+
+	if ioData, err := os.ReadFile(fmt.Sprintf("/proc/%d/io", pid)); err == nil {
+		for _, line := range strings.Split(string(ioData), "\n") {
+			if rest, ok := strings.CutPrefix(line, "read_bytes:"); ok {
+				ioBytes, _ = strconv.ParseUint(strings.TrimSpace(rest), 10, 64)
+			}
+		}
+	}
+
+	fds = countOpenFDs(pid)
+	return cpuUsec, rssBytes, ioBytes, fds, true
+}
+
+func countOpenFDs(pid int32) int {
+	entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/fd", pid))
+	if err != nil {
+		return 0
+	}
+	return len(entries)
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package main
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// noopResourceSampler is used on platforms without cgroup v2/procfs; it
+// warns once if a resource criterion is ever tracked, since it will never
+// fire.
+type noopResourceSampler struct {
+	warnOnce sync.Once
+}
+
+func newResourceSampler(w *ProcessWatcher, interval time.Duration) resourceSampler {
+	return &noopResourceSampler{}
+}
+
+func (s *noopResourceSampler) track(pid int32, c *Criterion) {
+	s.warnOnce.Do(func() {
+		log.Printf("Warning: criterion %q has a resource block, but resource sampling needs cgroup v2/procfs (Linux only)", c.Name)
+	})
+}
+
+func (s *noopResourceSampler) untrack(pid int32) {}
+
+func (s *noopResourceSampler) run(ctx context.Context) {
+	<-ctx.Done()
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// SourceCaps describes what a ProcessSource can actually observe, so callers
+// can warn when a configured criterion's "on" list asks for an event kind
+// the active backend will never deliver.
+type SourceCaps struct {
+	Events []string
+	Live   bool // true if events arrive as they happen; false for a diff-based poller
+}
+
+func (c SourceCaps) supports(kind string) bool {
+	for _, k := range c.Events {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessSource is a backend that turns OS-level process lifecycle
+// notifications into a stream of ProcEvent. netlinkSource (Linux CN_PROC),
+// kqueueSource (FreeBSD/OpenBSD/macOS EVFILT_PROC), and pollingSource (any
+// platform, snapshot diffing) are the concrete implementations; nativeSource
+// picks whichever of the first two is available on the build's GOOS.
+type ProcessSource interface {
+	Name() string
+	Capabilities() SourceCaps
+	Subscribe(ctx context.Context) (<-chan ProcEvent, error)
+}
+
+// pollingSource is the universal fallback: it has no kernel hook into
+// process creation, so it diffs successive snapshots of the process table.
+// For each newly seen pid it looks up its current Ppid and synthesizes a
+// fork (from that parent) immediately followed by an exec, and it
+// synthesizes an exit for each pid that disappears. It still can't see
+// setuid/setgid, comm renames, or coredumps.
+type pollingSource struct {
+	interval time.Duration
+}
+
+func newPollingSource(interval time.Duration) *pollingSource {
+	return &pollingSource{interval: interval}
+}
+
+func (s *pollingSource) Name() string { return "poll" }
+
+func (s *pollingSource) Capabilities() SourceCaps {
+	return SourceCaps{Events: []string{EventFork, EventExec, EventExit}, Live: false}
+}
+
+func snapshotPids() map[int32]struct{} {
+	pids, _ := process.Pids()
+	m := make(map[int32]struct{}, len(pids))
+	for _, pid := range pids {
+		m[pid] = struct{}{}
+	}
+	return m
+}
+
+func (s *pollingSource) Subscribe(ctx context.Context) (<-chan ProcEvent, error) {
+	ch := make(chan ProcEvent, 64)
+	go func() {
+		defer close(ch)
+		seen := snapshotPids()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := snapshotPids()
+				for pid := range current {
+					if _, ok := seen[pid]; !ok {
+						var parentPid int32
+						if p, err := process.NewProcess(pid); err == nil {
+							parentPid, _ = p.Ppid()
+						}
+						now := time.Now()
+						select {
+						case ch <- ProcEvent{Kind: EventFork, Pid: pid, ParentPid: parentPid, Timestamp: now}:
+						case <-ctx.Done():
+							return
+						}
+						select {
+						case ch <- ProcEvent{Kind: EventExec, Pid: pid, ParentPid: parentPid, Timestamp: now}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+				for pid := range seen {
+					if _, ok := current[pid]; !ok {
+						select {
+						case ch <- ProcEvent{Kind: EventExit, Pid: pid, Timestamp: time.Now()}:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+				seen = current
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// validSourceNames are the values accepted by the -source flag.
+var validSourceNames = map[string]bool{"auto": true, "netlink": true, "kqueue": true, "poll": true}
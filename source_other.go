@@ -0,0 +1,7 @@
+//go:build !linux && !darwin && !freebsd && !openbsd
+
+package main
+
+// nativeSource reports no kernel-backed source on platforms without a
+// CN_PROC or EVFILT_PROC implementation; the caller falls back to polling.
+func nativeSource() ProcessSource { return nil }
@@ -0,0 +1,318 @@
+// Per-criterion rate limiting, coalescing, and quiet hours — without this a
+// fork storm or crash loop turns one matched criterion into hundreds of
+// notifications a second, easily enough to wedge the notification daemon or
+// flood a webhook.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// criterionThrottle is the parsed, ready-to-use form of a criterion's
+// "throttle" config block. A nil *criterionThrottle on a Criterion means
+// every match is delivered immediately, unchanged from before throttling
+// existed.
+type criterionThrottle struct {
+	maxPerMinute   int
+	coalesceWindow time.Duration
+	cooldownAfter  time.Duration
+	quietHours     []quietHourRange
+}
+
+// quietHourRange is one parsed "HH:MM-HH:MM" local-time range. endMin <
+// startMin means the range wraps past midnight (e.g. 22:00-07:00).
+type quietHourRange struct {
+	startMin int
+	endMin   int
+}
+
+func (r quietHourRange) contains(minutesSinceMidnight int) bool {
+	if r.startMin <= r.endMin {
+		return minutesSinceMidnight >= r.startMin && minutesSinceMidnight < r.endMin
+	}
+	return minutesSinceMidnight >= r.startMin || minutesSinceMidnight < r.endMin
+}
+
+func parseClockHHMM(s string) (int, error) {
+	parts := strings.SplitN(s, ":", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q (want HH:MM)", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid hour in %q", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid minute in %q", s)
+	}
+	return h*60 + m, nil
+}
+
+func parseQuietHours(ranges []string) ([]quietHourRange, error) {
+	out := make([]quietHourRange, 0, len(ranges))
+	for _, r := range ranges {
+		parts := strings.SplitN(r, "-", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid quiet_hours range %q (want HH:MM-HH:MM)", r)
+		}
+		start, err := parseClockHHMM(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("quiet_hours %q: %w", r, err)
+		}
+		end, err := parseClockHHMM(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("quiet_hours %q: %w", r, err)
+		}
+		out = append(out, quietHourRange{startMin: start, endMin: end})
+	}
+	return out, nil
+}
+
+func inQuietHours(ranges []quietHourRange, t time.Time) bool {
+	minutes := t.Hour()*60 + t.Minute()
+	for _, r := range ranges {
+		if r.contains(minutes) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildThrottle(raw *throttleRaw) (*criterionThrottle, error) {
+	tc := &criterionThrottle{maxPerMinute: raw.MaxPerMinute}
+	if raw.CoalesceWindow != "" {
+		d, err := time.ParseDuration(raw.CoalesceWindow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid coalesce_window: %w", err)
+		}
+		tc.coalesceWindow = d
+	}
+	if raw.CooldownAfter != "" {
+		d, err := time.ParseDuration(raw.CooldownAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cooldown_after: %w", err)
+		}
+		tc.cooldownAfter = d
+	}
+	if len(raw.QuietHours) > 0 {
+		qh, err := parseQuietHours(raw.QuietHours)
+		if err != nil {
+			return nil, err
+		}
+		tc.quietHours = qh
+	}
+	return tc, nil
+}
+
+// pendingMatch is one match buffered during a criterion's coalesce_window,
+// awaiting the merged notification that replaces it.
+type pendingMatch struct {
+	ev    ProcEvent
+	title string
+}
+
+// throttleState is the runtime token-bucket/coalescing/cooldown state for
+// one criterion, keyed by criterion name on the throttler rather than on the
+// Criterion itself, so it survives a config reload that rebuilds the
+// Criterion slice.
+type throttleState struct {
+	mu sync.Mutex
+
+	tokens     float64
+	lastRefill time.Time
+
+	cooldownUntil time.Time
+
+	pending     []pendingMatch
+	windowTimer *time.Timer
+
+	suppressed int
+}
+
+// refill tops up the token bucket for elapsed time since the last refill,
+// capped at maxPerMinute. Callers must hold st.mu.
+func (st *throttleState) refill(maxPerMinute int, now time.Time) {
+	if maxPerMinute <= 0 {
+		return
+	}
+	if st.lastRefill.IsZero() {
+		st.tokens = float64(maxPerMinute)
+	} else {
+		st.tokens += now.Sub(st.lastRefill).Minutes() * float64(maxPerMinute)
+		if st.tokens > float64(maxPerMinute) {
+			st.tokens = float64(maxPerMinute)
+		}
+	}
+	st.lastRefill = now
+}
+
+// takeToken refills, then consumes one token if available. maxPerMinute <=
+// 0 means unlimited. Callers must hold st.mu.
+func (st *throttleState) takeToken(maxPerMinute int, now time.Time) bool {
+	if maxPerMinute <= 0 {
+		return true
+	}
+	st.refill(maxPerMinute, now)
+	if st.tokens < 1 {
+		return false
+	}
+	st.tokens--
+	return true
+}
+
+// throttler owns every criterion's throttleState and is where admit/flush
+// actually decide whether a match gets delivered, coalesced, or suppressed.
+type throttler struct {
+	w *ProcessWatcher
+
+	mu     sync.Mutex
+	states map[string]*throttleState
+}
+
+func newThrottler(w *ProcessWatcher) *throttler {
+	return &throttler{w: w, states: make(map[string]*throttleState)}
+}
+
+func (th *throttler) stateFor(name string) *throttleState {
+	th.mu.Lock()
+	defer th.mu.Unlock()
+	st, ok := th.states[name]
+	if !ok {
+		st = &throttleState{}
+		th.states[name] = st
+	}
+	return st
+}
+
+// admit decides what happens to one freshly matched (title, ev) pair: an
+// unthrottled criterion is delivered immediately; a throttled one is gated
+// by quiet hours, cooldown, coalescing, and the token bucket in that order.
+func (th *throttler) admit(c *Criterion, ev ProcEvent, title, body string) {
+	if c.throttle == nil {
+		th.w.dispatchActions(c, ev, RenderedMessage{Title: title, Body: body, Urgency: c.urgency})
+		return
+	}
+
+	tc := c.throttle
+	st := th.stateFor(c.Name)
+	st.mu.Lock()
+	now := time.Now()
+
+	if inQuietHours(tc.quietHours, now) {
+		st.suppressed++
+		st.mu.Unlock()
+		return
+	}
+	if now.Before(st.cooldownUntil) {
+		st.suppressed++
+		st.mu.Unlock()
+		return
+	}
+
+	if tc.coalesceWindow > 0 {
+		st.pending = append(st.pending, pendingMatch{ev: ev, title: title})
+		if st.windowTimer == nil {
+			st.windowTimer = time.AfterFunc(tc.coalesceWindow, func() { th.flush(c) })
+		}
+		st.mu.Unlock()
+		return
+	}
+
+	if !st.takeToken(tc.maxPerMinute, now) {
+		st.suppressed++
+		st.mu.Unlock()
+		return
+	}
+	if tc.cooldownAfter > 0 {
+		st.cooldownUntil = now.Add(tc.cooldownAfter)
+	}
+	st.mu.Unlock()
+
+	th.w.dispatchActions(c, ev, RenderedMessage{Title: title, Body: body, Urgency: c.urgency})
+}
+
+// flush delivers everything buffered in c's coalesce window as a single
+// notification, rendered from notify_body_coalesced. It's called by the
+// window timer started in admit, and once per throttled criterion on
+// shutdown so nothing buffered is silently dropped.
+func (th *throttler) flush(c *Criterion) {
+	st := th.stateFor(c.Name)
+	st.mu.Lock()
+	pending := st.pending
+	st.pending = nil
+	st.windowTimer = nil
+	if len(pending) == 0 {
+		st.mu.Unlock()
+		return
+	}
+
+	tc := c.throttle
+	now := time.Now()
+	if inQuietHours(tc.quietHours, now) || now.Before(st.cooldownUntil) || !st.takeToken(tc.maxPerMinute, now) {
+		st.suppressed += len(pending)
+		st.mu.Unlock()
+		return
+	}
+	if tc.cooldownAfter > 0 {
+		st.cooldownUntil = now.Add(tc.cooldownAfter)
+	}
+	st.mu.Unlock()
+
+	title, body := renderCoalesced(c, pending)
+	th.w.dispatchActions(c, pending[len(pending)-1].ev, RenderedMessage{Title: title, Body: body, Urgency: c.urgency})
+}
+
+// renderCoalesced merges pending into one (title, body) pair: the title of
+// the most recent match, and a body rendered from notify_body_coalesced with
+// {count} and {pids} (comma-separated, oldest first) added to {event}.
+func renderCoalesced(c *Criterion, pending []pendingMatch) (string, string) {
+	last := pending[len(pending)-1]
+	pids := make([]string, len(pending))
+	for i, p := range pending {
+		pids[i] = fmt.Sprintf("%d", p.ev.Pid)
+	}
+	ctx := map[string]string{
+		"event": last.ev.Kind,
+		"count": fmt.Sprintf("%d", len(pending)),
+		"pids":  strings.Join(pids, ","),
+	}
+	return last.title, formatTemplate(c.notifyBodyCoalesced, ctx)
+}
+
+// shutdown flushes every throttled criterion's pending coalesce buffer so a
+// process_watcher restart doesn't silently drop matches that were waiting
+// out their window.
+func (th *throttler) shutdown() {
+	for _, c := range th.w.listCriteria() {
+		if c.throttle == nil {
+			continue
+		}
+		st := th.stateFor(c.Name)
+		st.mu.Lock()
+		if st.windowTimer != nil {
+			st.windowTimer.Stop()
+			st.windowTimer = nil
+		}
+		st.mu.Unlock()
+		th.flush(c)
+	}
+}
+
+// snapshot reports c's current token count (rounded down towards zero) and
+// lifetime suppressed-match count, for the gRPC ListCriteria RPC. A
+// criterion with no throttle block reports (0, 0).
+func (th *throttler) snapshot(c *Criterion) (tokensRemaining, suppressedCount int) {
+	if c.throttle == nil {
+		return 0, 0
+	}
+	st := th.stateFor(c.Name)
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.refill(c.throttle.maxPerMinute, time.Now())
+	return int(st.tokens), st.suppressed
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHourRangeContains(t *testing.T) {
+	cases := []struct {
+		name    string
+		r       quietHourRange
+		minutes int
+		want    bool
+	}{
+		{"inside same-day range", quietHourRange{startMin: 9 * 60, endMin: 17 * 60}, 12 * 60, true},
+		{"before same-day range", quietHourRange{startMin: 9 * 60, endMin: 17 * 60}, 8 * 60, false},
+		{"at start boundary", quietHourRange{startMin: 9 * 60, endMin: 17 * 60}, 9 * 60, true},
+		{"at end boundary is excluded", quietHourRange{startMin: 9 * 60, endMin: 17 * 60}, 17 * 60, false},
+		{"wrap after midnight", quietHourRange{startMin: 22 * 60, endMin: 7 * 60}, 1 * 60, true},
+		{"wrap before midnight", quietHourRange{startMin: 22 * 60, endMin: 7 * 60}, 23 * 60, true},
+		{"wrap outside range", quietHourRange{startMin: 22 * 60, endMin: 7 * 60}, 12 * 60, false},
+		{"wrap at start boundary", quietHourRange{startMin: 22 * 60, endMin: 7 * 60}, 22 * 60, true},
+		{"wrap at end boundary is excluded", quietHourRange{startMin: 22 * 60, endMin: 7 * 60}, 7 * 60, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.r.contains(c.minutes); got != c.want {
+				t.Errorf("contains(%d) = %v, want %v", c.minutes, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	cases := []struct {
+		name    string
+		ranges  []string
+		wantErr bool
+	}{
+		{"valid same-day", []string{"09:00-17:30"}, false},
+		{"valid wrap", []string{"22:00-07:00"}, false},
+		{"missing dash", []string{"09:00"}, true},
+		{"missing colon", []string{"0900-1730"}, true},
+		{"hour out of range", []string{"24:00-01:00"}, true},
+		{"minute out of range", []string{"09:60-10:00"}, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := parseQuietHours(c.ranges)
+			if (err != nil) != c.wantErr {
+				t.Errorf("parseQuietHours(%v) error = %v, wantErr %v", c.ranges, err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestThrottleStateTakeToken(t *testing.T) {
+	st := &throttleState{}
+	now := time.Now()
+
+	// Unlimited when maxPerMinute <= 0.
+	if !st.takeToken(0, now) {
+		t.Fatal("takeToken with maxPerMinute 0 should always allow")
+	}
+
+	st = &throttleState{}
+	if !st.takeToken(2, now) {
+		t.Fatal("first token should be available on an empty bucket (seeded full)")
+	}
+	if !st.takeToken(2, now) {
+		t.Fatal("second token should be available immediately after seeding")
+	}
+	if st.takeToken(2, now) {
+		t.Fatal("bucket should be exhausted after consuming maxPerMinute tokens")
+	}
+
+	// Refilling after half a minute with maxPerMinute=2 adds ~1 token.
+	later := now.Add(30 * time.Second)
+	if !st.takeToken(2, later) {
+		t.Fatal("token should refill after elapsed time")
+	}
+	if st.takeToken(2, later) {
+		t.Fatal("only one token should have refilled after 30s at 2/min")
+	}
+}
+
+func TestBuildThrottle(t *testing.T) {
+	raw := &throttleRaw{
+		MaxPerMinute:   5,
+		CoalesceWindow: "10s",
+		CooldownAfter:  "1m",
+		QuietHours:     []string{"22:00-07:00"},
+	}
+	tc, err := buildThrottle(raw)
+	if err != nil {
+		t.Fatalf("buildThrottle: %v", err)
+	}
+	if tc.maxPerMinute != 5 {
+		t.Errorf("maxPerMinute = %d, want 5", tc.maxPerMinute)
+	}
+	if tc.coalesceWindow != 10*time.Second {
+		t.Errorf("coalesceWindow = %v, want 10s", tc.coalesceWindow)
+	}
+	if tc.cooldownAfter != time.Minute {
+		t.Errorf("cooldownAfter = %v, want 1m", tc.cooldownAfter)
+	}
+	if len(tc.quietHours) != 1 {
+		t.Errorf("quietHours = %v, want 1 entry", tc.quietHours)
+	}
+
+	if _, err := buildThrottle(&throttleRaw{CoalesceWindow: "not-a-duration"}); err == nil {
+		t.Error("buildThrottle with invalid coalesce_window should error")
+	}
+	if _, err := buildThrottle(&throttleRaw{CooldownAfter: "not-a-duration"}); err == nil {
+		t.Error("buildThrottle with invalid cooldown_after should error")
+	}
+	if _, err := buildThrottle(&throttleRaw{QuietHours: []string{"bogus"}}); err == nil {
+		t.Error("buildThrottle with invalid quiet_hours should error")
+	}
+}
+
+func TestRenderCoalesced(t *testing.T) {
+	c := &Criterion{notifyBodyCoalesced: "{count} matching processes: {pids}"}
+	pending := []pendingMatch{
+		{ev: ProcEvent{Kind: "exec", Pid: 100}, title: "first"},
+		{ev: ProcEvent{Kind: "exec", Pid: 200}, title: "second"},
+	}
+	title, body := renderCoalesced(c, pending)
+	if title != "second" {
+		t.Errorf("title = %q, want %q (most recent match)", title, "second")
+	}
+	if want := "2 matching processes: 100,200"; body != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}